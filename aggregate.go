@@ -0,0 +1,389 @@
+package gmongo
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Aggregates - Metrics computed for a group of documents
+type Aggregates[V Number] struct {
+	Count int64   `json:"count"`
+	Sum   V       `json:"sum"`
+	Avg   float64 `json:"avg"`
+	Min   V       `json:"min"`
+	Max   V       `json:"max"`
+}
+
+// DecimalAggregates - Metrics computed for a group of documents without losing precision, see StatsDecimal
+type DecimalAggregates struct {
+	Count int64                `json:"count"`
+	Sum   primitive.Decimal128 `json:"sum"`
+	Avg   primitive.Decimal128 `json:"avg"`
+	Min   primitive.Decimal128 `json:"min"`
+	Max   primitive.Decimal128 `json:"max"`
+}
+
+// toNumber - Convert a numeric BSON value to the requested type V.
+// Decimal128 values are converted through their string representation.
+func toNumber[V Number](value interface{}) (V, bool) {
+	switch v := value.(type) {
+	case float64:
+		return V(v), true
+	case int32:
+		return V(v), true
+	case int64:
+		return V(v), true
+	case int:
+		return V(v), true
+	case primitive.Decimal128:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil {
+			return V(0), false
+		}
+		return V(f), true
+	}
+	return V(0), false
+}
+
+// toDecimal - Convert a numeric BSON value to a Decimal128
+func toDecimal(value interface{}) (primitive.Decimal128, bool) {
+	var str string
+	switch v := value.(type) {
+	case primitive.Decimal128:
+		return v, true
+	case float64:
+		str = strconv.FormatFloat(v, 'f', -1, 64)
+	case int32:
+		str = strconv.FormatInt(int64(v), 10)
+	case int64:
+		str = strconv.FormatInt(v, 10)
+	case int:
+		str = strconv.Itoa(v)
+	default:
+		return primitive.Decimal128{}, false
+	}
+
+	d, err := primitive.ParseDecimal128(str)
+	return d, err == nil
+}
+
+// decodeValue - Decode a value returned by the driver into V.
+// Values that are not already of type V (e.g. int32 into int) are converted
+// by round-tripping them through bson with registry.
+func decodeValue[V any](registry *bsoncodec.Registry, value interface{}) (V, error) {
+	if v, ok := value.(V); ok {
+		return v, nil
+	}
+
+	var wrapper struct {
+		Value V `bson:"v"`
+	}
+
+	raw, err := marshalWith(registry, bson.M{"v": value})
+	if err != nil {
+		return wrapper.Value, err
+	}
+
+	if err = unmarshalWith(registry, raw, &wrapper); err != nil {
+		return wrapper.Value, fmt.Errorf("gmongo: cannot decode %T into %T: %w", value, wrapper.Value, err)
+	}
+
+	return wrapper.Value, nil
+}
+
+// matchPipeline - Start a pipeline with a $match stage if filter is set
+func matchPipeline(filter interface{}) bson.A {
+	pipeline := bson.A{}
+	if filter != nil {
+		pipeline = append(pipeline, bson.M{"$match": filter})
+	}
+	return pipeline
+}
+
+// decimalOf - Expression converting key to a Decimal128
+func decimalOf(key string) bson.M {
+	return bson.M{"$toDecimal": fmt.Sprintf("$%s", key)}
+}
+
+// addAccumulators - Add sum, avg, min and max accumulators of value to a $group stage.
+// The accumulators are named prefix+"sum", prefix+"avg" etc.
+func addAccumulators(group bson.M, prefix string, value interface{}) {
+	group[prefix+"sum"] = bson.M{"$sum": value}
+	group[prefix+"avg"] = bson.M{"$avg": value}
+	group[prefix+"min"] = bson.M{"$min": value}
+	group[prefix+"max"] = bson.M{"$max": value}
+}
+
+// readAggregates - Read the accumulators added by addAccumulators from a $group result
+func readAggregates[V Number](data bson.M, prefix string) Aggregates[V] {
+	var res Aggregates[V]
+	res.Count, _ = toNumber[int64](data[prefix+"count"])
+	res.Sum, _ = toNumber[V](data[prefix+"sum"])
+	res.Avg, _ = toNumber[float64](data[prefix+"avg"])
+	res.Min, _ = toNumber[V](data[prefix+"min"])
+	res.Max, _ = toNumber[V](data[prefix+"max"])
+	return res
+}
+
+// readDecimalAggregates - Same as readAggregates, for accumulators of decimalOf values
+func readDecimalAggregates(data bson.M, prefix string) DecimalAggregates {
+	var res DecimalAggregates
+	res.Count, _ = toNumber[int64](data[prefix+"count"])
+	res.Sum, _ = toDecimal(data[prefix+"sum"])
+	res.Avg, _ = toDecimal(data[prefix+"avg"])
+	res.Min, _ = toDecimal(data[prefix+"min"])
+	res.Max, _ = toDecimal(data[prefix+"max"])
+	return res
+}
+
+// Distinct - Get the distinct values of a field
+//
+// Example:
+//
+//	names, _ := gmongo.Distinct[string](UserModel, "name", bson.M{"verified": true})
+//	// names will be ["John", "Doe"]
+func Distinct[V any, T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], field string, filter interface{}) ([]V, error) {
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	values, err := coll.Native().Distinct(coll.ctx(), field, filter)
+	if err != nil {
+		return []V{}, err
+	}
+
+	registry := coll.registry()
+	result := make([]V, 0, len(values))
+	for _, value := range values {
+		v, err := decodeValue[V](registry, value)
+		if err != nil {
+			return []V{}, err
+		}
+		result = append(result, v)
+	}
+
+	return result, nil
+}
+
+// GroupBy - Group documents by a field and compute count, sum, avg, min and max of another field
+//
+// Example:
+// Data: [
+//
+//	{name: "John", country: "NG", credit: 100},
+//	{name: "Doe", country: "NG", credit: 300},
+//	{name: "Jane", country: "GH", credit: 200},
+//
+// ]
+//
+//	groups, _ := gmongo.GroupBy[string, int](UserModel, "country", "credit", nil)
+//	// groups["NG"] will be {Count: 2, Sum: 400, Avg: 200, Min: 100, Max: 300}
+func GroupBy[K comparable, V Number, T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], groupKey string, valueKey string, filter interface{}) (map[K]Aggregates[V], error) {
+	result := map[K]Aggregates[V]{}
+
+	group := bson.M{
+		"_id":   fmt.Sprintf("$%s", groupKey),
+		"count": bson.M{"$sum": 1},
+	}
+	addAccumulators(group, "", fmt.Sprintf("$%s", valueKey))
+
+	pipeline := append(matchPipeline(filter), bson.M{"$group": group})
+
+	res, err := coll.Aggregate(pipeline)
+	if err != nil {
+		return result, err
+	}
+
+	registry := coll.registry()
+	for _, data := range res {
+		key, err := decodeValue[K](registry, data["_id"])
+		if err != nil {
+			return result, err
+		}
+
+		result[key] = readAggregates[V](data, "")
+	}
+
+	return result, nil
+}
+
+// Stats - Compute count, sum, avg, min and max of many keys in one query.
+// Count is the number of matched documents where the key holds a number.
+// Decimal128 values are converted to V through float64, use StatsDecimal to keep their precision.
+//
+// Example:
+// Data: [
+//
+//	{name: "John", credit: 100, debit: 400},
+//	{name: "Doe", credit: 200, debit: 300},
+//
+// ]
+//
+//	stats, _ := gmongo.Stats[int](UserModel, []string{"credit", "debit"}, nil)
+//	// stats["credit"] will be {Count: 2, Sum: 300, Avg: 150, Min: 100, Max: 200}
+func Stats[V Number, T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], keys []string, filter interface{}) (map[string]Aggregates[V], error) {
+	result := map[string]Aggregates[V]{}
+	group := bson.M{"_id": nil}
+
+	for i, key := range keys {
+		addStatsAccumulators(group, i, key, fmt.Sprintf("$%s", key))
+		result[key] = Aggregates[V]{}
+	}
+
+	res, err := coll.Aggregate(append(matchPipeline(filter), bson.M{"$group": group}))
+	if err != nil || len(res) == 0 {
+		return result, err
+	}
+
+	for i, key := range keys {
+		result[key] = readAggregates[V](res[0], fmt.Sprintf("k%d_", i))
+	}
+
+	return result, nil
+}
+
+// StatsDecimal - Same as Stats, without losing precision.
+//
+// Values are converted with $toDecimal, like SumManyDecimal, so averages of
+// Decimal128 fields (e.g. money) are not rounded through float64.
+//
+//	stats, _ := gmongo.StatsDecimal(UserModel, []string{"credit"}, nil)
+//	// stats["credit"].Avg will be Decimal128("150.25")
+func StatsDecimal[T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], keys []string, filter interface{}) (map[string]DecimalAggregates, error) {
+	result := map[string]DecimalAggregates{}
+	group := bson.M{"_id": nil}
+
+	for i, key := range keys {
+		addStatsAccumulators(group, i, key, decimalOf(key))
+		result[key] = DecimalAggregates{}
+	}
+
+	res, err := coll.Aggregate(append(matchPipeline(filter), bson.M{"$group": group}))
+	if err != nil || len(res) == 0 {
+		return result, err
+	}
+
+	for i, key := range keys {
+		result[key] = readDecimalAggregates(res[0], fmt.Sprintf("k%d_", i))
+	}
+
+	return result, nil
+}
+
+// addStatsAccumulators - Add the count and the accumulators of value, the i-th key of Stats, to group
+func addStatsAccumulators(group bson.M, i int, key string, value interface{}) {
+	// keys may be dotted paths, which are not valid $group field names
+	prefix := fmt.Sprintf("k%d_", i)
+	group[prefix+"count"] = bson.M{"$sum": bson.M{
+		"$cond": bson.A{bson.M{"$isNumber": fmt.Sprintf("$%s", key)}, 1, 0},
+	}}
+	addAccumulators(group, prefix, value)
+}
+
+// aggregateOne - Run a single accumulator over value and return the raw result
+func aggregateOne[T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], accumulator string, value interface{}, filter interface{}) (interface{}, error) {
+	pipeline := append(matchPipeline(filter), bson.M{"$group": bson.M{
+		"_id":   nil,
+		"value": bson.M{accumulator: value},
+	}})
+
+	res, err := coll.Aggregate(pipeline)
+	if err != nil || len(res) == 0 {
+		return nil, err
+	}
+
+	return res[0]["value"], nil
+}
+
+// Avg - Average of a key
+//
+//	avg, _ := gmongo.Avg(UserModel, "credit", nil)
+//	// avg will be 150
+func Avg[T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], key string, filter interface{}) (float64, error) {
+	value, err := aggregateOne(coll, "$avg", fmt.Sprintf("$%s", key), filter)
+	res, _ := toNumber[float64](value)
+	return res, err
+}
+
+// Min - Minimum value of a key
+//
+//	min, _ := gmongo.Min[int](UserModel, "credit", nil)
+//	// min will be 100
+func Min[V Number, T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], key string, filter interface{}) (V, error) {
+	value, err := aggregateOne(coll, "$min", fmt.Sprintf("$%s", key), filter)
+	res, _ := toNumber[V](value)
+	return res, err
+}
+
+// Max - Maximum value of a key
+//
+//	max, _ := gmongo.Max[int](UserModel, "credit", nil)
+//	// max will be 200
+func Max[V Number, T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], key string, filter interface{}) (V, error) {
+	value, err := aggregateOne(coll, "$max", fmt.Sprintf("$%s", key), filter)
+	res, _ := toNumber[V](value)
+	return res, err
+}
+
+// SumManyDecimal - Sum many documents without losing precision.
+//
+// Values are converted with $toDecimal before summing, so mixed int, double
+// and Decimal128 fields (e.g. money) are summed exactly.
+//
+//	sum, _ := gmongo.SumManyDecimal(Model, []string{"credit", "debit"}, nil)
+//	// sum will be {credit: Decimal128("300.50"), debit: Decimal128("700")}
+func SumManyDecimal[T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], keys []string, filter interface{}) (bson.M, error) {
+	group := bson.M{"_id": nil}
+	result := bson.M{}
+
+	for i, key := range keys {
+		group[fmt.Sprintf("k%d", i)] = bson.M{"$sum": bson.M{"$toDecimal": fmt.Sprintf("$%s", key)}}
+		result[key], _ = toDecimal(0)
+	}
+
+	pipeline := append(matchPipeline(filter), bson.M{"$group": group})
+
+	res, err := coll.Aggregate(pipeline)
+	if err != nil || len(res) == 0 {
+		return result, err
+	}
+
+	for i, key := range keys {
+		if d, ok := toDecimal(res[0][fmt.Sprintf("k%d", i)]); ok {
+			result[key] = d
+		}
+	}
+
+	return result, nil
+}
+
+// SumDecimal - Sum a key without losing precision, see SumManyDecimal
+func SumDecimal[T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], key string, filter interface{}) (primitive.Decimal128, error) {
+	res, err := SumManyDecimal(coll, []string{key}, filter)
+	return res[key].(primitive.Decimal128), err
+}
+
+// AvgDecimal - Average of a key without losing precision, see StatsDecimal
+func AvgDecimal[T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], key string, filter interface{}) (primitive.Decimal128, error) {
+	value, err := aggregateOne(coll, "$avg", decimalOf(key), filter)
+	res, _ := toDecimal(value)
+	return res, err
+}
+
+// MinDecimal - Minimum value of a key as a Decimal128, see StatsDecimal
+func MinDecimal[T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], key string, filter interface{}) (primitive.Decimal128, error) {
+	value, err := aggregateOne(coll, "$min", decimalOf(key), filter)
+	res, _ := toDecimal(value)
+	return res, err
+}
+
+// MaxDecimal - Maximum value of a key as a Decimal128, see StatsDecimal
+func MaxDecimal[T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], key string, filter interface{}) (primitive.Decimal128, error) {
+	value, err := aggregateOne(coll, "$max", decimalOf(key), filter)
+	res, _ := toDecimal(value)
+	return res, err
+}
@@ -0,0 +1,80 @@
+package gmongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_toNumber(t *testing.T) {
+	v, ok := toNumber[int](int32(10))
+	assert.True(t, ok)
+	assert.Equal(t, 10, v)
+
+	f, ok := toNumber[float64](int64(10))
+	assert.True(t, ok)
+	assert.Equal(t, float64(10), f)
+
+	d, _ := primitive.ParseDecimal128("10.25")
+	f, ok = toNumber[float64](d)
+	assert.True(t, ok)
+	assert.Equal(t, 10.25, f)
+
+	_, ok = toNumber[int]("10")
+	assert.False(t, ok)
+}
+
+func Test_toDecimal(t *testing.T) {
+	d, ok := toDecimal(int32(10))
+	assert.True(t, ok)
+	assert.Equal(t, "10", d.String())
+
+	d, ok = toDecimal(10.25)
+	assert.True(t, ok)
+	assert.Equal(t, "10.25", d.String())
+
+	_, ok = toDecimal("10")
+	assert.False(t, ok)
+}
+
+func Test_readDecimalAggregates(t *testing.T) {
+	avg, _ := primitive.ParseDecimal128("0.3333333333333333333333333333333333")
+	res := readDecimalAggregates(bson.M{"k0_count": int32(3), "k0_sum": int64(1), "k0_avg": avg, "k0_min": int32(0)}, "k0_")
+	assert.Equal(t, int64(3), res.Count)
+	assert.Equal(t, "1", res.Sum.String())
+	assert.Equal(t, avg, res.Avg)
+	assert.Equal(t, "0", res.Min.String())
+	assert.Equal(t, primitive.Decimal128{}, res.Max)
+}
+
+func Test_decodeValue(t *testing.T) {
+	i, err := decodeValue[int](nil, int32(20))
+	assert.NoError(t, err)
+	assert.Equal(t, 20, i)
+
+	s, err := decodeValue[string](nil, "John")
+	assert.NoError(t, err)
+	assert.Equal(t, "John", s)
+
+	id := NewId()
+	oid, err := decodeValue[primitive.ObjectID](nil, id)
+	assert.NoError(t, err)
+	assert.Equal(t, id, oid)
+
+	_, err = decodeValue[int](nil, "John")
+	assert.Error(t, err)
+
+	t.Run("Uses the registry", func(t *testing.T) {
+		registry := NewRegistry(WithDurationStrings())
+
+		d, err := decodeValue[time.Duration](registry, "1h30m0s")
+		assert.NoError(t, err)
+		assert.Equal(t, 90*time.Minute, d)
+
+		_, err = decodeValue[time.Duration](nil, "1h30m0s")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,151 @@
+package gmongo
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultAuditCollection - Collection of audit entries when AuditOptions.Collection is empty
+const DefaultAuditCollection = "gmongo_audit"
+
+// AuditOperation - The kind of write an audit entry records
+type AuditOperation string
+
+const (
+	AuditInsert AuditOperation = "insert"
+	AuditUpdate AuditOperation = "update"
+	AuditDelete AuditOperation = "delete"
+)
+
+// AuditChange - The value of a field before and after a write
+type AuditChange struct {
+	Before interface{} `bson:"before,omitempty" json:"before,omitempty"`
+	After  interface{} `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// AuditEntry - A recorded write of an audited model
+type AuditEntry struct {
+	ID         primitive.ObjectID     `bson:"_id" json:"id"`
+	Collection string                 `bson:"collection" json:"collection"`
+	DocumentID interface{}            `bson:"documentId" json:"documentId"`
+	Operation  AuditOperation         `bson:"operation" json:"operation"`
+	Actor      interface{}            `bson:"actor,omitempty" json:"actor,omitempty"`
+	Filter     interface{}            `bson:"filter,omitempty" json:"filter,omitempty"`
+	Changes    map[string]AuditChange `bson:"changes" json:"changes"`
+	CreatedAt  time.Time              `bson:"createdAt" json:"createdAt"`
+}
+
+// GetID - Get the ID of the entry
+func (e *AuditEntry) GetID() primitive.ObjectID {
+	return e.ID
+}
+
+// AuditOptions - Options of Model[T].EnableAudit
+type AuditOptions struct {
+	// Collection - Collection entries are written to, defaults to DefaultAuditCollection
+	Collection string
+	// Actor - Resolves the actor of a write from its context, defaults to ActorFromContext
+	Actor func(ctx context.Context) interface{}
+}
+
+type actorContextKey struct{}
+
+// WithActor - Return a copy of ctx carrying the actor recorded by audited writes
+//
+//	ctx = gmongo.WithActor(ctx, user.ID)
+//	_, err := UserModel.WithContext(ctx).UpdateOne(...)
+func WithActor(ctx context.Context, actor interface{}) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext - Get the actor set by WithActor, nil if none
+func ActorFromContext(ctx context.Context) interface{} {
+	return ctx.Value(actorContextKey{})
+}
+
+// EnableAudit - Record every document written by the insert, update, replace
+// and delete methods and the FindOneAnd methods (including ModelHelper writes)
+// of the model in an audit collection. FindOneAnd methods cannot use a
+// projection once audit or history is enabled.
+//
+// Entries are written with the context of the model, so writes through
+// WithTx (or a transaction context) are audited in the same transaction.
+// Outside a transaction the document is read before and after the write,
+// so a concurrent write may show up in the recorded diff.
+func (coll *ModelOf[T, ID]) EnableAudit(opts ...AuditOptions) {
+	opt := AuditOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Collection == "" {
+		opt.Collection = DefaultAuditCollection
+	}
+	if opt.Actor == nil {
+		opt.Actor = ActorFromContext
+	}
+
+	coll.audit = &opt
+}
+
+// AuditTrail - Get the audit entries of a document, oldest first
+func (coll *ModelOf[T, ID]) AuditTrail(id ID) ([]AuditEntry, error) {
+	name := DefaultAuditCollection
+	if coll.audit != nil {
+		name = coll.audit.Collection
+	}
+
+	cursor, err := coll.Native().Database().Collection(name).Find(coll.ctx(),
+		bson.M{"collection": coll.CollectionName, "documentId": id},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]AuditEntry, 0)
+	if err = cursor.All(coll.ctx(), &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// diffDocuments - Get the top level fields that differ between before and after
+func diffDocuments(before, after bson.M) map[string]AuditChange {
+	changes := map[string]AuditChange{}
+
+	for key, value := range before {
+		if next, ok := after[key]; !ok || !reflect.DeepEqual(value, next) {
+			changes[key] = AuditChange{Before: value, After: after[key]}
+		}
+	}
+	for key, value := range after {
+		if _, ok := before[key]; !ok {
+			changes[key] = AuditChange{After: value}
+		}
+	}
+
+	return changes
+}
+
+// writeAuditEntry - Write an audit entry for a write of the model
+func (coll *ModelOf[T, ID]) writeAuditEntry(op AuditOperation, id, filter interface{}, before, after bson.M) error {
+	entry := AuditEntry{
+		ID:         primitive.NewObjectID(),
+		Collection: coll.CollectionName,
+		DocumentID: id,
+		Operation:  op,
+		Actor:      coll.audit.Actor(coll.ctx()),
+		Filter:     filter,
+		Changes:    diffDocuments(before, after),
+		CreatedAt:  time.Now(),
+	}
+
+	_, err := coll.Native().Database().Collection(coll.audit.Collection).InsertOne(coll.ctx(), entry)
+	return err
+}
@@ -0,0 +1,178 @@
+package gmongo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func Test_diffDocuments(t *testing.T) {
+	changes := diffDocuments(
+		bson.M{"name": "John", "age": 20, "verified": false},
+		bson.M{"name": "John", "age": 21, "email": "john@example.com"},
+	)
+
+	assert.Equal(t, map[string]AuditChange{
+		"age":      {Before: 20, After: 21},
+		"verified": {Before: false},
+		"email":    {After: "john@example.com"},
+	}, changes)
+
+	assert.Empty(t, diffDocuments(bson.M{"a": 1}, bson.M{"a": 1}))
+	assert.Equal(t, map[string]AuditChange{"a": {After: 1}}, diffDocuments(nil, bson.M{"a": 1}))
+}
+
+func Test_ActorFromContext(t *testing.T) {
+	assert.Nil(t, ActorFromContext(context.TODO()))
+	assert.Equal(t, "admin", ActorFromContext(WithActor(context.TODO(), "admin")))
+}
+
+func Test_toDocument(t *testing.T) {
+	sub := &Subscriber{ID: primitive.NewObjectID(), Plan: PlanPro, Trial: time.Hour}
+
+	doc, err := toDocument(NewRegistry(WithTextMarshalers(), WithDurationStrings()), sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "pro", doc["plan"])
+	assert.Equal(t, "1h0m0s", doc["trial"])
+
+	doc, err = toDocument(nil, sub)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(PlanPro), doc["plan"])
+	assert.Equal(t, int64(time.Hour), doc["trial"])
+}
+
+func Test_readTarget(t *testing.T) {
+	id := primitive.NewObjectID()
+	before := bson.M{"_id": id, "version": 1}
+
+	assert.Equal(t, bson.M{"$and": bson.A{bson.M{"version": 1}, bson.M{"_id": id}}}, readTarget(bson.M{"version": 1}, before))
+	assert.Equal(t, bson.M{"$and": bson.A{bson.M{}, bson.M{"_id": id}}}, readTarget(nil, before))
+}
+
+func TestAudit(t *testing.T) {
+	client := testConnectToDb()
+
+	UserModel := MakeModel[*User](client.Database, "audit_users")
+	UserModel.EnableAudit(AuditOptions{Collection: "test_audit"})
+	audit := client.Database.Collection("test_audit")
+
+	reset := func() {
+		_, _ = UserModel.Native().DeleteMany(context.TODO(), bson.M{})
+		_, _ = audit.DeleteMany(context.TODO(), bson.M{})
+	}
+
+	t.Run("Records writes with actor and diff", func(t *testing.T) {
+		reset()
+
+		users := UserModel.WithContext(WithActor(context.TODO(), "admin"))
+		user := &User{ID: primitive.NewObjectID(), Name: "John", Age: 20}
+
+		_, err := users.InsertOne(user)
+		assert.Nil(t, err)
+		_, err = users.Helpers(user).Update(bson.M{"age": 21})
+		assert.Nil(t, err)
+		// no-op updates are not recorded
+		_, err = users.UpdateOne(bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"age": 21}})
+		assert.Nil(t, err)
+		_, err = users.DeleteOne(bson.M{"name": "John"})
+		assert.Nil(t, err)
+
+		entries, err := UserModel.AuditTrail(user.ID)
+		assert.Nil(t, err)
+		assert.Len(t, entries, 3)
+
+		assert.Equal(t, AuditInsert, entries[0].Operation)
+		assert.Equal(t, "John", entries[0].Changes["name"].After)
+
+		assert.Equal(t, AuditUpdate, entries[1].Operation)
+		assert.Equal(t, "admin", entries[1].Actor)
+		assert.Equal(t, AuditChange{Before: int32(20), After: int32(21)}, entries[1].Changes["age"])
+		assert.NotContains(t, entries[1].Changes, "name")
+
+		assert.Equal(t, AuditDelete, entries[2].Operation)
+		assert.Equal(t, "John", entries[2].Changes["name"].Before)
+		assert.Nil(t, entries[2].Changes["name"].After)
+	})
+
+	t.Run("Skips writes that match nothing", func(t *testing.T) {
+		reset()
+
+		_, err := UserModel.UpdateOne(bson.M{"name": "Nobody"}, bson.M{"$set": bson.M{"age": 1}})
+		assert.Nil(t, err)
+		_, err = UserModel.DeleteOne(bson.M{"name": "Nobody"})
+		assert.Nil(t, err)
+
+		count, _ := audit.CountDocuments(context.TODO(), bson.M{})
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("Keeps the conditions of the filter", func(t *testing.T) {
+		reset()
+
+		user := &User{ID: primitive.NewObjectID(), Name: "John", Age: 20}
+		_, _ = UserModel.InsertOne(user)
+
+		// compare-and-set: only one of the writers sees age 20
+		var modified int64
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, err := UserModel.UpdateOne(bson.M{"_id": user.ID, "age": 20}, bson.M{"$inc": bson.M{"age": 1}})
+				assert.Nil(t, err)
+				atomic.AddInt64(&modified, res.ModifiedCount)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int64(1), modified)
+		found, _ := UserModel.FindOneById(user.ID)
+		assert.Equal(t, 21, found.Age)
+
+		res, err := UserModel.DeleteOne(bson.M{"_id": user.ID, "age": 20})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), res.DeletedCount)
+
+		entries, _ := UserModel.AuditTrail(user.ID)
+		assert.Len(t, entries, 2)
+	})
+
+	t.Run("Upserts", func(t *testing.T) {
+		reset()
+
+		res, err := UserModel.UpdateOne(bson.M{"name": "Doe"}, bson.M{"$set": bson.M{"age": 30}}, options.Update().SetUpsert(true))
+		assert.Nil(t, err)
+		assert.NotNil(t, res.UpsertedID)
+
+		res, err = UserModel.UpdateOne(bson.M{"name": "Doe"}, bson.M{"$set": bson.M{"age": 31}}, options.Update().SetUpsert(true))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), res.ModifiedCount)
+
+		count, _ := audit.CountDocuments(context.TODO(), bson.M{})
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Writes entries in the transaction", func(t *testing.T) {
+		requireReplicaSet(t, client)
+		reset()
+
+		user := &User{ID: primitive.NewObjectID(), Name: "John"}
+		_ = client.Transaction(func(tx *Tx) error {
+			_, _ = UserModel.WithTx(tx).InsertOne(user)
+			return errors.New("rollback")
+		})
+
+		entries, err := UserModel.AuditTrail(user.ID)
+		assert.Nil(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,280 @@
+package gmongo
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Codec - Encodes and decodes values of a type, see WithCodec
+type Codec interface {
+	bsoncodec.ValueEncoder
+	bsoncodec.ValueDecoder
+}
+
+// CodecOption - Adds codecs to the registry of NewRegistry
+type CodecOption func(registry *bsoncodec.Registry)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// NewRegistry - Create the default BSON registry with the codecs of gmongo
+// (UUID) and the given options. ConnectUsingString uses it unless
+// options.Client().SetRegistry is passed:
+//
+//	client, err := gmongo.ConnectUsingString(uri, "app", options.Client().SetRegistry(gmongo.NewRegistry(
+//		gmongo.WithTextMarshalers(),
+//		gmongo.WithNilSliceAsEmpty(),
+//		gmongo.WithCodec(reflect.TypeOf(decimal.Decimal{}), DecimalCodec{}),
+//	)))
+func NewRegistry(opts ...CodecOption) *bsoncodec.Registry {
+	registry := bson.NewRegistry()
+	registry.RegisterTypeEncoder(uuidType, UUIDCodec{})
+	registry.RegisterTypeDecoder(uuidType, UUIDCodec{})
+
+	for _, opt := range opts {
+		opt(registry)
+	}
+
+	return registry
+}
+
+// marshalWith - Marshal a document with registry, bson.DefaultRegistry if nil
+func marshalWith(registry *bsoncodec.Registry, doc interface{}) (bson.Raw, error) {
+	if registry == nil {
+		registry = bson.DefaultRegistry
+	}
+
+	buf := new(bytes.Buffer)
+	vw, err := bsonrw.NewBSONValueWriter(buf)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := bson.NewEncoder(vw)
+	if err != nil {
+		return nil, err
+	}
+	if err = enc.SetRegistry(registry); err != nil {
+		return nil, err
+	}
+	if err = enc.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// unmarshalWith - Unmarshal a document with registry, bson.DefaultRegistry if nil
+func unmarshalWith(registry *bsoncodec.Registry, data []byte, result interface{}) error {
+	if registry == nil {
+		registry = bson.DefaultRegistry
+	}
+
+	dec, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(data))
+	if err != nil {
+		return err
+	}
+	if err = dec.SetRegistry(registry); err != nil {
+		return err
+	}
+
+	return dec.Decode(result)
+}
+
+// marshalValueWith - Marshal a single value with registry, bson.DefaultRegistry if nil
+func marshalValueWith(registry *bsoncodec.Registry, value interface{}) (bson.RawValue, error) {
+	raw, err := marshalWith(registry, bson.M{"v": value})
+	if err != nil {
+		return bson.RawValue{}, err
+	}
+	return raw.Lookup("v"), nil
+}
+
+// WithCodec - Encode and decode values of type t with codec
+func WithCodec(t reflect.Type, codec Codec) CodecOption {
+	return func(registry *bsoncodec.Registry) {
+		registry.RegisterTypeEncoder(t, codec)
+		registry.RegisterTypeDecoder(t, codec)
+	}
+}
+
+// WithTextMarshalers - Store types implementing encoding.TextMarshaler (e.g.
+// enums) as strings, decoded with encoding.TextUnmarshaler. Types with their
+// own codec, such as time.Time, primitive.ObjectID and UUID, and pointers to
+// them are unchanged.
+func WithTextMarshalers() CodecOption {
+	return func(registry *bsoncodec.Registry) {
+		registry.RegisterInterfaceEncoder(textMarshalerType, TextMarshalerCodec{})
+		registry.RegisterInterfaceDecoder(textUnmarshalerType, TextMarshalerCodec{})
+	}
+}
+
+// WithDurationStrings - Store time.Duration as a string such as "1h30m"
+// instead of nanoseconds. Nanoseconds are still decoded.
+func WithDurationStrings() CodecOption {
+	return WithCodec(durationType, DurationCodec{})
+}
+
+// WithNilSliceAsEmpty - Store nil slices as empty arrays instead of null
+func WithNilSliceAsEmpty() CodecOption {
+	return func(registry *bsoncodec.Registry) {
+		// EncodeNilAsEmpty is deprecated in favour of options.BSONOptions, which
+		// applies to the whole client rather than to a registry
+		registry.RegisterKindEncoder(reflect.Slice, &bsoncodec.SliceCodec{EncodeNilAsEmpty: true})
+	}
+}
+
+// TextMarshalerCodec - Encodes encoding.TextMarshaler values as strings, see WithTextMarshalers
+type TextMarshalerCodec struct{}
+
+// EncodeValue - Encode the value as the string of MarshalText
+func (TextMarshalerCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() {
+		return bsoncodec.ValueEncoderError{Name: "TextMarshalerCodec.EncodeValue", Types: []reflect.Type{textMarshalerType}, Received: val}
+	}
+
+	// pointers are encoded by the codec of their element, so *time.Time keeps
+	// the codec of time.Time
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return vw.WriteNull()
+		}
+		encoder, err := ec.LookupEncoder(val.Type().Elem())
+		if err != nil {
+			return err
+		}
+		return encoder.EncodeValue(ec, vw, val.Elem())
+	}
+
+	var marshaler encoding.TextMarshaler
+	switch {
+	case val.Type().Implements(textMarshalerType):
+		marshaler = val.Interface().(encoding.TextMarshaler)
+	case val.CanAddr() && val.Addr().Type().Implements(textMarshalerType):
+		marshaler = val.Addr().Interface().(encoding.TextMarshaler)
+	default:
+		return bsoncodec.ValueEncoderError{Name: "TextMarshalerCodec.EncodeValue", Types: []reflect.Type{textMarshalerType}, Received: val}
+	}
+
+	text, err := marshaler.MarshalText()
+	if err != nil {
+		return err
+	}
+
+	return vw.WriteString(string(text))
+}
+
+// DecodeValue - Decode a string with UnmarshalText
+func (TextMarshalerCodec) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() {
+		return bsoncodec.ValueDecoderError{Name: "TextMarshalerCodec.DecodeValue", Types: []reflect.Type{textUnmarshalerType}, Received: val}
+	}
+
+	// pointers are decoded by the codec of their element, so *time.Time keeps
+	// the codec of time.Time
+	if val.Kind() == reflect.Ptr {
+		if vr.Type() == bsontype.Null {
+			val.Set(reflect.Zero(val.Type()))
+			return vr.ReadNull()
+		}
+		decoder, err := dc.LookupDecoder(val.Type().Elem())
+		if err != nil {
+			return err
+		}
+		ptr := reflect.New(val.Type().Elem())
+		if err = decoder.DecodeValue(dc, vr, ptr.Elem()); err != nil {
+			return err
+		}
+		val.Set(ptr)
+		return nil
+	}
+
+	var text string
+	switch vr.Type() {
+	case bsontype.String:
+		s, err := vr.ReadString()
+		if err != nil {
+			return err
+		}
+		text = s
+	case bsontype.Null:
+		val.Set(reflect.Zero(val.Type()))
+		return vr.ReadNull()
+	default:
+		return fmt.Errorf("gmongo: cannot decode %s into %s", vr.Type(), val.Type())
+	}
+
+	ptr := reflect.New(val.Type())
+	unmarshaler, ok := ptr.Interface().(encoding.TextUnmarshaler)
+	if !ok {
+		return bsoncodec.ValueDecoderError{Name: "TextMarshalerCodec.DecodeValue", Types: []reflect.Type{textUnmarshalerType}, Received: val}
+	}
+	if err := unmarshaler.UnmarshalText([]byte(text)); err != nil {
+		return err
+	}
+
+	val.Set(ptr.Elem())
+	return nil
+}
+
+// DurationCodec - Encodes time.Duration as a string, see WithDurationStrings
+type DurationCodec struct{}
+
+// EncodeValue - Encode the duration as its String form
+func (DurationCodec) EncodeValue(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Type() != durationType {
+		return bsoncodec.ValueEncoderError{Name: "DurationCodec.EncodeValue", Types: []reflect.Type{durationType}, Received: val}
+	}
+
+	return vw.WriteString(time.Duration(val.Int()).String())
+}
+
+// DecodeValue - Decode a duration from a string or a number of nanoseconds
+func (DurationCodec) DecodeValue(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != durationType {
+		return bsoncodec.ValueDecoderError{Name: "DurationCodec.DecodeValue", Types: []reflect.Type{durationType}, Received: val}
+	}
+
+	var d time.Duration
+	switch vr.Type() {
+	case bsontype.String:
+		s, err := vr.ReadString()
+		if err != nil {
+			return err
+		}
+		if d, err = time.ParseDuration(s); err != nil {
+			return err
+		}
+	case bsontype.Int64:
+		n, err := vr.ReadInt64()
+		if err != nil {
+			return err
+		}
+		d = time.Duration(n)
+	case bsontype.Int32:
+		n, err := vr.ReadInt32()
+		if err != nil {
+			return err
+		}
+		d = time.Duration(n)
+	case bsontype.Null:
+		if err := vr.ReadNull(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("gmongo: cannot decode %s into a time.Duration", vr.Type())
+	}
+
+	val.SetInt(int64(d))
+	return nil
+}
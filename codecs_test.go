@@ -0,0 +1,217 @@
+package gmongo
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Plan int
+
+const (
+	PlanFree Plan = iota
+	PlanPro
+)
+
+func (p Plan) MarshalText() ([]byte, error) {
+	switch p {
+	case PlanFree:
+		return []byte("free"), nil
+	case PlanPro:
+		return []byte("pro"), nil
+	}
+	return nil, errors.New("unknown plan")
+}
+
+func (p *Plan) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "free":
+		*p = PlanFree
+	case "pro":
+		*p = PlanPro
+	default:
+		return errors.New("unknown plan " + string(text))
+	}
+	return nil
+}
+
+// upperString - Stored upper cased, to test WithCodec
+type upperString string
+
+type upperStringCodec struct{}
+
+func (upperStringCodec) EncodeValue(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	return vw.WriteString(strings.ToUpper(val.String()))
+}
+
+func (upperStringCodec) DecodeValue(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	s, err := vr.ReadString()
+	val.SetString(strings.ToLower(s))
+	return err
+}
+
+type Subscriber struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Plan      Plan               `bson:"plan"`
+	Previous  *Plan              `bson:"previous"`
+	Trial     time.Duration      `bson:"trial"`
+	Tags      []string           `bson:"tags"`
+	Code      upperString        `bson:"code"`
+	CreatedAt time.Time          `bson:"createdAt"`
+}
+
+func (s *Subscriber) GetID() primitive.ObjectID { return s.ID }
+
+func TestNewRegistry(t *testing.T) {
+	registry := NewRegistry(
+		WithTextMarshalers(),
+		WithDurationStrings(),
+		WithNilSliceAsEmpty(),
+		WithCodec(reflect.TypeOf(upperString("")), upperStringCodec{}),
+	)
+
+	pro := PlanPro
+	sub := Subscriber{
+		ID:        primitive.NewObjectID(),
+		Plan:      PlanPro,
+		Previous:  &pro,
+		Trial:     90 * time.Minute,
+		Code:      "abc",
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	data, err := bson.MarshalWithRegistry(registry, sub)
+	assert.Nil(t, err)
+
+	raw := bson.Raw(data)
+	assert.Equal(t, "pro", raw.Lookup("plan").StringValue())
+	assert.Equal(t, "pro", raw.Lookup("previous").StringValue())
+	assert.Equal(t, "1h30m0s", raw.Lookup("trial").StringValue())
+	assert.Equal(t, bson.TypeArray, raw.Lookup("tags").Type)
+	assert.Equal(t, "ABC", raw.Lookup("code").StringValue())
+	// types with their own codec are unchanged
+	assert.Equal(t, bson.TypeDateTime, raw.Lookup("createdAt").Type)
+	assert.Equal(t, bson.TypeObjectID, raw.Lookup("_id").Type)
+
+	var decoded Subscriber
+	assert.Nil(t, bson.UnmarshalWithRegistry(registry, data, &decoded))
+	assert.Equal(t, PlanPro, decoded.Plan)
+	assert.Equal(t, PlanPro, *decoded.Previous)
+	assert.Equal(t, 90*time.Minute, decoded.Trial)
+	assert.Equal(t, []string{}, decoded.Tags)
+	assert.Equal(t, upperString("abc"), decoded.Code)
+	assert.True(t, sub.CreatedAt.Equal(decoded.CreatedAt))
+
+	t.Run("Decodes nanosecond durations and null", func(t *testing.T) {
+		data, _ := bson.Marshal(bson.M{"trial": int64(time.Second), "previous": nil})
+
+		var decoded Subscriber
+		assert.Nil(t, bson.UnmarshalWithRegistry(registry, data, &decoded))
+		assert.Equal(t, time.Second, decoded.Trial)
+		assert.Nil(t, decoded.Previous)
+	})
+
+	t.Run("Rejects unknown enum values", func(t *testing.T) {
+		data, _ := bson.Marshal(bson.M{"plan": "gold"})
+
+		var decoded Subscriber
+		assert.NotNil(t, bson.UnmarshalWithRegistry(registry, data, &decoded))
+	})
+
+	t.Run("Pointers to types with their own codec are unchanged", func(t *testing.T) {
+		type pointers struct {
+			At   *time.Time          `bson:"at"`
+			ID   *primitive.ObjectID `bson:"id"`
+			UUID *UUID               `bson:"uuid"`
+			Plan *Plan               `bson:"plan"`
+			None *time.Time          `bson:"none"`
+		}
+
+		at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		id := primitive.NewObjectID()
+		uuid := NewUUID()
+		pro := PlanPro
+		data, err := bson.MarshalWithRegistry(registry, pointers{At: &at, ID: &id, UUID: &uuid, Plan: &pro})
+		assert.Nil(t, err)
+
+		raw := bson.Raw(data)
+		assert.Equal(t, bson.TypeDateTime, raw.Lookup("at").Type)
+		assert.Equal(t, bson.TypeObjectID, raw.Lookup("id").Type)
+		assert.Equal(t, bson.TypeBinary, raw.Lookup("uuid").Type)
+		assert.Equal(t, "pro", raw.Lookup("plan").StringValue())
+		assert.Equal(t, bson.TypeNull, raw.Lookup("none").Type)
+
+		var decoded pointers
+		assert.Nil(t, bson.UnmarshalWithRegistry(registry, data, &decoded))
+		assert.True(t, at.Equal(*decoded.At))
+		assert.Equal(t, id, *decoded.ID)
+		assert.Equal(t, uuid, *decoded.UUID)
+		assert.Equal(t, PlanPro, *decoded.Plan)
+		assert.Nil(t, decoded.None)
+
+		// dates stored by the driver decode into pointer fields
+		data, _ = bson.Marshal(bson.M{"lockedUntil": at, "sentAt": at})
+		var msg OutboxMessage
+		assert.Nil(t, bson.UnmarshalWithRegistry(registry, data, &msg))
+		assert.True(t, at.Equal(*msg.LockedUntil))
+		assert.True(t, at.Equal(*msg.SentAt))
+	})
+
+	t.Run("Codecs are opt-in", func(t *testing.T) {
+		data, err := bson.MarshalWithRegistry(NewRegistry(), sub)
+		assert.Nil(t, err)
+
+		raw := bson.Raw(data)
+		assert.Equal(t, bson.TypeInt64, raw.Lookup("trial").Type)
+		assert.Equal(t, bson.TypeNull, raw.Lookup("tags").Type)
+	})
+}
+
+func TestConnectUsingString_Registry(t *testing.T) {
+	uri := os.Getenv("GMONGO_TEST_URI")
+	if uri == "" {
+		uri = "mongodb://localhost:27017"
+	}
+
+	registry := NewRegistry(WithTextMarshalers(), WithDurationStrings(), WithNilSliceAsEmpty())
+	client, err := ConnectUsingString(uri, "gmongo", options.Client().SetRegistry(registry))
+	assert.Nil(t, err)
+	assert.Same(t, registry, client.Registry)
+
+	SubscriberModel := MakeClientModel[*Subscriber](client, "subscribers")
+	_, _ = SubscriberModel.DeleteMany(bson.M{})
+
+	sub := &Subscriber{ID: primitive.NewObjectID(), Plan: PlanPro, Trial: time.Hour}
+	_, err = SubscriberModel.InsertOne(sub)
+	assert.Nil(t, err)
+
+	// stored with the codecs, so queries use the encoded form
+	count, err := SubscriberModel.Count(bson.M{"plan": "pro", "trial": "1h0m0s", "tags": bson.A{}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	found, err := SubscriberModel.FindOneById(sub.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, PlanPro, found.Plan)
+	assert.Equal(t, time.Hour, found.Trial)
+
+	// filters are encoded with the registry too
+	count, err = SubscriberModel.Count(bson.M{"plan": PlanPro})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// values decoded by the model use the registry of the client
+	trials, err := Distinct[time.Duration](&SubscriberModel, "trial", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []time.Duration{time.Hour}, trials)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"sync"
 
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -12,7 +14,17 @@ import (
 type Client struct {
 	MongoClient *mongo.Client
 	Database    *mongo.Database
-	connected   bool
+	// OutboxCollection - Collection used by Tx.Publish and OutboxDispatcher, defaults to DefaultOutboxCollection
+	OutboxCollection string
+	// Registry - The BSON registry the client was connected with, see NewRegistry.
+	// Models made with MakeClientModel or LinkClientModel also use it to convert
+	// documents internally (audit, history, encryption, aggregation results).
+	// Set it when wrapping a mongo.Client connected with a custom registry.
+	Registry *bsoncodec.Registry
+	// CountersCollection - Collection used by Sequence, defaults to DefaultCountersCollection
+	CountersCollection string
+	connected          bool
+	sequences          sync.Map
 }
 
 type ConnectionCredentials struct {
@@ -25,8 +37,21 @@ func (c *Client) IsConnected() bool {
 	return c.connected
 }
 
-func ConnectUsingString(connectionString string, database string) (*Client, error) {
-	mongoClient, err := mongo.Connect(context.TODO(), options.Client().ApplyURI(connectionString))
+// ConnectUsingString - Connect to a database. The registry of NewRegistry is
+// used, opts are applied after the connection string and can override it.
+func ConnectUsingString(connectionString string, database string, opts ...*options.ClientOptions) (*Client, error) {
+	registry := NewRegistry()
+	for _, opt := range opts {
+		if opt != nil && opt.Registry != nil {
+			registry = opt.Registry
+		}
+	}
+
+	clientOpts := append([]*options.ClientOptions{
+		options.Client().ApplyURI(connectionString).SetRegistry(registry),
+	}, opts...)
+
+	mongoClient, err := mongo.Connect(context.TODO(), clientOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -34,13 +59,14 @@ func ConnectUsingString(connectionString string, database string) (*Client, erro
 	gmongoClient := &Client{
 		MongoClient: mongoClient,
 		Database:    mongoClient.Database(database),
+		Registry:    registry,
 		connected:   true,
 	}
 
 	return gmongoClient, nil
 }
 
-func ConnectUsingCredentials(credentials *ConnectionCredentials) (*Client, error) {
+func ConnectUsingCredentials(credentials *ConnectionCredentials, opts ...*options.ClientOptions) (*Client, error) {
 	DbServer := credentials.DbServer
 	if DbServer == "" {
 		return nil, errors.New("DbServer is empty")
@@ -55,48 +81,5 @@ func ConnectUsingCredentials(credentials *ConnectionCredentials) (*Client, error
 		return nil, errors.New("DbName is empty")
 	}
 
-	return ConnectUsingString(DbServer, DbName)
-}
-
-// Tx - A transaction handle passed to the callback of Client.Transaction.
-// Use Tx.Context() to enroll raw mongo-driver calls in the transaction, or
-// Tx.Collection(name) for native access to a collection that has no Model.
-type Tx struct {
-	sc mongo.SessionContext
-	db *mongo.Database
-}
-
-// Context returns the session context. Pass it to any raw mongo-driver call
-// to enroll that op in the transaction.
-func (t *Tx) Context() mongo.SessionContext { return t.sc }
-
-// Database returns the *mongo.Database the transaction is running on.
-func (t *Tx) Database() *mongo.Database { return t.db }
-
-// Collection is sugar for tx.Database().Collection(name) — convenient for
-// native ops on collections that don't have a gmongo Model.
-func (t *Tx) Collection(name string) *mongo.Collection { return t.db.Collection(name) }
-
-// Transaction runs fn inside a MongoDB transaction. The session lifecycle is
-// managed for the caller. Return an error from fn to abort; nil to commit.
-//
-// Inside fn, use Model[T].WithTx(tx) to get a transaction-bound model, or
-// tx.Context() / tx.Collection(name) for native mongo-driver access.
-//
-// Note: MongoDB transactions require a replica set or sharded cluster.
-func (c *Client) Transaction(fn func(tx *Tx) error, opts ...*options.TransactionOptions) error {
-	session, err := c.MongoClient.StartSession()
-	if err != nil {
-		return err
-	}
-	defer session.EndSession(context.TODO())
-
-	_, err = session.WithTransaction(
-		context.TODO(),
-		func(sc mongo.SessionContext) (interface{}, error) {
-			return nil, fn(&Tx{sc: sc, db: c.Database})
-		},
-		opts...,
-	)
-	return err
+	return ConnectUsingString(DbServer, DbName, opts...)
 }
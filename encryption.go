@@ -0,0 +1,705 @@
+package gmongo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BinaryEncrypted - BSON binary subtype of encrypted values, from the user defined range
+const BinaryEncrypted byte = 0x80
+
+// encryptionVersion - Version of the encrypted value format:
+// version | mode | key id length | key id | nonce | AES-GCM ciphertext
+const encryptionVersion byte = 1
+
+// EncryptMode - How a field tagged `gmongo:"encrypt"` is encrypted
+type EncryptMode byte
+
+const (
+	// EncryptRandom - A random nonce per value, the default. Fields can't be queried.
+	EncryptRandom EncryptMode = iota
+	// EncryptDeterministic - Equal values encrypt equally with the same key, so
+	// fields tagged `gmongo:"encrypt=deterministic"` can be used in equality filters.
+	// Equal values can be told apart from different ones by anyone reading the
+	// collection, so prefer EncryptRandom when fields are not queried.
+	EncryptDeterministic
+)
+
+// ErrDecrypt - Returned when an encrypted value can't be decrypted
+var ErrDecrypt = errors.New("gmongo: cannot decrypt value")
+
+// KeyProvider - Provides the AES keys (16, 24 or 32 bytes) of encrypted fields
+type KeyProvider interface {
+	// CurrentKeyID - ID of the key new values are encrypted with
+	CurrentKeyID() string
+	// Key - Get a key by ID, including retired keys that still decrypt older values
+	Key(id string) ([]byte, error)
+	// KeyIDs - IDs of all keys, used to query deterministic fields encrypted with older keys
+	KeyIDs() []string
+}
+
+// LocalKeyProvider - A KeyProvider holding its keys in memory, see LoadKeyFile
+type LocalKeyProvider struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewLocalKeyProvider - Create a key provider encrypting with the key `current`
+func NewLocalKeyProvider(current string, keys map[string][]byte) (*LocalKeyProvider, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("gmongo: current key %q is missing", current)
+	}
+	for id, key := range keys {
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("gmongo: key %q: %w", id, err)
+		}
+	}
+
+	return &LocalKeyProvider{current: current, keys: keys}, nil
+}
+
+// LoadKeyFile - Load a LocalKeyProvider from a JSON file of base64 keys, e.g.
+//
+//	{"current": "2024-06", "keys": {"2024-01": "...", "2024-06": "..."}}
+//
+// To rotate keys, add a key, make it current and run Model[T].RotateEncryptionKeys.
+func LoadKeyFile(path string) (*LocalKeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Current string            `json:"current"`
+		Keys    map[string][]byte `json:"keys"`
+	}
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	return NewLocalKeyProvider(file.Current, file.Keys)
+}
+
+// GenerateKey - Generate a random AES-256 key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	return key, err
+}
+
+// CurrentKeyID - ID of the key new values are encrypted with
+func (p *LocalKeyProvider) CurrentKeyID() string {
+	return p.current
+}
+
+// Key - Get a key by ID
+func (p *LocalKeyProvider) Key(id string) ([]byte, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("gmongo: unknown key %q", id)
+	}
+	return key, nil
+}
+
+// KeyIDs - IDs of all keys, sorted
+func (p *LocalKeyProvider) KeyIDs() []string {
+	ids := make([]string, 0, len(p.keys))
+	for id := range p.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// fieldEncryption - The encrypted fields of a model and their keys
+type fieldEncryption struct {
+	provider KeyProvider
+	// fields - Encrypt mode by bson field name
+	fields map[string]EncryptMode
+	// registry - Registry values are marshalled with, bson.DefaultRegistry if nil
+	registry *bsoncodec.Registry
+}
+
+// EnableEncryption - Encrypt fields tagged `gmongo:"encrypt"` (or
+// `gmongo:"encrypt=deterministic"`) with AES-GCM.
+//
+// Tagged fields of documents and of $set / $setOnInsert updates are encrypted
+// on insert, update and replace, and decrypted by Find, FindOne, FindAs,
+// FindOneAs, the FindOneAnd methods, the Paginate methods (including the data
+// and facet values of Facets and PaginateFacet) and the events of Watch and
+// Subscribe. Deterministic fields are
+// also encrypted in the top level (or $and / $or / $nor) equality, $in and $nin
+// conditions of filters. Aggregation stages see the encrypted values.
+//
+//	type Customer struct {
+//		ID    primitive.ObjectID `bson:"_id"`
+//		Phone string             `bson:"phone" gmongo:"encrypt"`
+//		Email string             `bson:"email" gmongo:"encrypt=deterministic"`
+//	}
+func (coll *ModelOf[T, ID]) EnableEncryption(provider KeyProvider) {
+	var zero T
+	coll.encryption = &fieldEncryption{
+		provider: provider,
+		fields:   encryptedFields(reflect.TypeOf(zero)),
+	}
+}
+
+// encryptedFields - Get the encrypted fields of a struct type and its inline structs
+func encryptedFields(t reflect.Type) map[string]EncryptMode {
+	fields := map[string]EncryptMode{}
+	if t == nil {
+		return fields
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts := parseTag(field.Tag.Get("bson"))
+		if name == "-" {
+			continue
+		}
+		if hasTagOpt(opts, "inline") || (field.Anonymous && name == "") {
+			for key, mode := range encryptedFields(field.Type) {
+				fields[key] = mode
+			}
+			continue
+		}
+
+		value, ok := tagOption(field.Tag.Get("gmongo"), "encrypt")
+		if !ok {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		fields[name] = EncryptRandom
+		if value == "deterministic" {
+			fields[name] = EncryptDeterministic
+		}
+	}
+
+	return fields
+}
+
+// seal - Encrypt plaintext with a key
+func seal(keyID string, key []byte, mode EncryptMode, plaintext []byte) (primitive.Binary, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return primitive.Binary{}, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return primitive.Binary{}, err
+	}
+
+	if len(keyID) > 255 {
+		return primitive.Binary{}, fmt.Errorf("gmongo: key id %q is longer than 255 bytes", keyID)
+	}
+	header := append([]byte{encryptionVersion, byte(mode), byte(len(keyID))}, keyID...)
+
+	nonce := make([]byte, gcm.NonceSize())
+	if mode == EncryptDeterministic {
+		// derive the nonce from the plaintext with a key separate from the AES key
+		nonceKey := hmac.New(sha256.New, key)
+		nonceKey.Write([]byte("gmongo deterministic nonce"))
+		mac := hmac.New(sha256.New, nonceKey.Sum(nil))
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err = rand.Read(nonce); err != nil {
+		return primitive.Binary{}, err
+	}
+
+	// the output must not share memory with header, which is authenticated
+	data := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+gcm.Overhead())
+	data = append(append(data, header...), nonce...)
+	data = gcm.Seal(data, nonce, plaintext, header)
+
+	return primitive.Binary{Subtype: BinaryEncrypted, Data: data}, nil
+}
+
+// isEncrypted - Check if a value was encrypted by seal
+func isEncrypted(value interface{}) (primitive.Binary, bool) {
+	bin, ok := value.(primitive.Binary)
+	return bin, ok && bin.Subtype == BinaryEncrypted && len(bin.Data) > 3 && bin.Data[0] == encryptionVersion
+}
+
+// encryptedKeyID - Get the ID of the key a value was encrypted with
+func encryptedKeyID(bin primitive.Binary) string {
+	size := int(bin.Data[2])
+	if len(bin.Data) < 3+size {
+		return ""
+	}
+	return string(bin.Data[3 : 3+size])
+}
+
+// encryptWith - Encrypt a value with the key keyID
+func (e *fieldEncryption) encryptWith(keyID string, value interface{}, mode EncryptMode) (primitive.Binary, error) {
+	raw, err := marshalValueWith(e.registry, value)
+	if err != nil {
+		return primitive.Binary{}, err
+	}
+
+	key, err := e.provider.Key(keyID)
+	if err != nil {
+		return primitive.Binary{}, err
+	}
+
+	return seal(keyID, key, mode, append([]byte{byte(raw.Type)}, raw.Value...))
+}
+
+// encrypt - Encrypt a value with the current key, nil and encrypted values are unchanged
+func (e *fieldEncryption) encrypt(value interface{}, mode EncryptMode) (interface{}, error) {
+	if _, ok := isEncrypted(value); ok || value == nil {
+		return value, nil
+	}
+	return e.encryptWith(e.provider.CurrentKeyID(), value, mode)
+}
+
+// decrypt - Decrypt a value encrypted by seal
+func (e *fieldEncryption) decrypt(bin primitive.Binary) (interface{}, error) {
+	data := bin.Data
+	size := int(data[2])
+	if len(data) < 3+size {
+		return nil, ErrDecrypt
+	}
+	header, rest := data[:3+size], data[3+size:]
+
+	key, err := e.provider.Key(string(header[3:]))
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	plaintext, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], header)
+	if err != nil || len(plaintext) == 0 {
+		return nil, ErrDecrypt
+	}
+
+	var value interface{}
+	raw := bson.RawValue{Type: bsontype.Type(plaintext[0]), Value: plaintext[1:]}
+	if err = raw.UnmarshalWithRegistry(e.registryOrDefault(), &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// encryptFields - Encrypt the encrypted fields of a document
+func (e *fieldEncryption) encryptFields(doc interface{}) (interface{}, error) {
+	return mapDocument(doc, func(key string, value interface{}) (interface{}, error) {
+		mode, ok := e.fields[key]
+		if !ok {
+			return value, nil
+		}
+		return e.encrypt(value, mode)
+	})
+}
+
+// encryptDocument - Convert a document to a bson.D with its encrypted fields
+// encrypted, fields are stored in the order of doc
+func (e *fieldEncryption) encryptDocument(doc interface{}) (interface{}, error) {
+	d, err := toOrderedDocument(e.registry, doc)
+	if err != nil {
+		return nil, err
+	}
+	return e.encryptFields(d)
+}
+
+// encryptUpdate - Encrypt the encrypted fields of $set and $setOnInsert
+func (e *fieldEncryption) encryptUpdate(update interface{}) (interface{}, error) {
+	return mapDocument(update, func(key string, value interface{}) (interface{}, error) {
+		if key != "$set" && key != "$setOnInsert" {
+			return value, nil
+		}
+		return e.encryptFields(value)
+	})
+}
+
+// filterValues - Encrypt a filter value with every key, so values encrypted
+// with retired keys match too
+func (e *fieldEncryption) filterValues(value interface{}) (bson.A, error) {
+	values := bson.A{}
+	for _, id := range e.provider.KeyIDs() {
+		encrypted, err := e.encryptWith(id, value, EncryptDeterministic)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, encrypted)
+	}
+	return values, nil
+}
+
+// filterCondition - Encrypt the condition of a deterministic field
+func (e *fieldEncryption) filterCondition(value interface{}) (interface{}, error) {
+	operator, operand, isOperator := singleOperator(value)
+	if !isOperator {
+		operator, operand = "$eq", value
+	}
+
+	var values bson.A
+	switch operator {
+	case "$eq", "$ne":
+		encrypted, err := e.filterValues(operand)
+		if err != nil {
+			return nil, err
+		}
+		values = encrypted
+	case "$in", "$nin":
+		list := reflect.ValueOf(operand)
+		if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+			return value, nil
+		}
+		for i := 0; i < list.Len(); i++ {
+			encrypted, err := e.filterValues(list.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, encrypted...)
+		}
+	default:
+		return value, nil
+	}
+
+	if operator == "$ne" || operator == "$nin" {
+		return bson.M{"$nin": values}, nil
+	}
+	if len(values) == 1 {
+		return values[0], nil
+	}
+	return bson.M{"$in": values}, nil
+}
+
+// encryptFilter - Encrypt the conditions of deterministic fields of a filter
+func (e *fieldEncryption) encryptFilter(filter interface{}) (interface{}, error) {
+	return mapDocument(filter, func(key string, value interface{}) (interface{}, error) {
+		if key == "$and" || key == "$or" || key == "$nor" {
+			return mapArray(value, e.encryptFilter)
+		}
+		if mode, ok := e.fields[key]; ok && mode == EncryptDeterministic {
+			return e.filterCondition(value)
+		}
+		return value, nil
+	})
+}
+
+// decryptValue - Decrypt the encrypted values of a decoded value, recursively
+func (e *fieldEncryption) decryptValue(value interface{}) (interface{}, error) {
+	if bin, ok := isEncrypted(value); ok {
+		return e.decrypt(bin)
+	}
+
+	switch value.(type) {
+	case bson.M, bson.D:
+		return mapDocument(value, func(_ string, v interface{}) (interface{}, error) {
+			return e.decryptValue(v)
+		})
+	case bson.A:
+		return mapArray(value, e.decryptValue)
+	}
+	return value, nil
+}
+
+// decryptRaw - Decrypt a raw document and decode it into result, keeping the
+// order of its fields
+func (e *fieldEncryption) decryptRaw(raw bson.Raw, result interface{}) error {
+	var doc bson.D
+	if err := unmarshalWith(e.registry, raw, &doc); err != nil {
+		return err
+	}
+
+	decrypted, err := e.decryptValue(doc)
+	if err != nil {
+		return err
+	}
+
+	data, err := marshalWith(e.registry, decrypted)
+	if err != nil {
+		return err
+	}
+	return unmarshalWith(e.registry, data, result)
+}
+
+// decryptAll - Decrypt raw documents and decode them into results, a pointer to a slice
+func (e *fieldEncryption) decryptAll(raws []bson.Raw, results interface{}) error {
+	slice := reflect.ValueOf(results)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("gmongo: results must be a pointer to a slice, got %T", results)
+	}
+	slice = slice.Elem()
+
+	decoded := reflect.MakeSlice(slice.Type(), 0, len(raws))
+	for _, raw := range raws {
+		elem := reflect.New(slice.Type().Elem())
+		if err := e.decryptRaw(raw, elem.Interface()); err != nil {
+			return err
+		}
+		decoded = reflect.Append(decoded, elem.Elem())
+	}
+
+	slice.Set(decoded)
+	return nil
+}
+
+// singleOperator - Get the operator of a condition such as {"$in": [...]}
+func singleOperator(value interface{}) (string, interface{}, bool) {
+	switch cond := value.(type) {
+	case bson.M:
+		if len(cond) == 1 {
+			for op, operand := range cond {
+				return op, operand, strings.HasPrefix(op, "$")
+			}
+		}
+	case bson.D:
+		if len(cond) == 1 {
+			return cond[0].Key, cond[0].Value, strings.HasPrefix(cond[0].Key, "$")
+		}
+	}
+	return "", nil, false
+}
+
+// mapDocument - Map the values of a bson.M, map[string]interface{} or bson.D,
+// other values are returned unchanged
+func mapDocument(doc interface{}, fn func(key string, value interface{}) (interface{}, error)) (interface{}, error) {
+	switch d := doc.(type) {
+	case bson.M:
+		res := make(bson.M, len(d))
+		for key, value := range d {
+			mapped, err := fn(key, value)
+			if err != nil {
+				return nil, err
+			}
+			res[key] = mapped
+		}
+		return res, nil
+	case map[string]interface{}:
+		return mapDocument(bson.M(d), fn)
+	case bson.D:
+		res := make(bson.D, len(d))
+		for i, e := range d {
+			mapped, err := fn(e.Key, e.Value)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = bson.E{Key: e.Key, Value: mapped}
+		}
+		return res, nil
+	}
+	return doc, nil
+}
+
+// mapArray - Map the items of a bson.A, []interface{} or []bson.M
+func mapArray(arr interface{}, fn func(value interface{}) (interface{}, error)) (interface{}, error) {
+	var items []interface{}
+	switch a := arr.(type) {
+	case bson.A:
+		items = a
+	case []interface{}:
+		items = a
+	case []bson.M:
+		for _, m := range a {
+			items = append(items, m)
+		}
+	default:
+		return arr, nil
+	}
+
+	res := make(bson.A, len(items))
+	for i, item := range items {
+		mapped, err := fn(item)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = mapped
+	}
+	return res, nil
+}
+
+// encrypter - The encryption of the model using the registry of its client
+func (coll *ModelOf[T, ID]) encrypter() *fieldEncryption {
+	e := *coll.encryption
+	e.registry = coll.registry()
+	return &e
+}
+
+// registryOrDefault - The registry of e, bson.DefaultRegistry if not set
+func (e *fieldEncryption) registryOrDefault() *bsoncodec.Registry {
+	if e.registry == nil {
+		return bson.DefaultRegistry
+	}
+	return e.registry
+}
+
+// encryptFilter - Encrypt the deterministic fields of a filter when encryption is enabled
+func (coll *ModelOf[T, ID]) encryptFilter(filter interface{}) (interface{}, error) {
+	if coll.encryption == nil {
+		return filter, nil
+	}
+	return coll.encrypter().encryptFilter(filter)
+}
+
+// encryptUpdate - Encrypt the fields set by an update when encryption is enabled
+func (coll *ModelOf[T, ID]) encryptUpdate(update interface{}) (interface{}, error) {
+	if coll.encryption == nil {
+		return update, nil
+	}
+	return coll.encrypter().encryptUpdate(update)
+}
+
+// encryptDocument - Encrypt the fields of a document when encryption is enabled
+func (coll *ModelOf[T, ID]) encryptDocument(doc interface{}) (interface{}, error) {
+	if coll.encryption == nil {
+		return doc, nil
+	}
+	return coll.encrypter().encryptDocument(doc)
+}
+
+// encryptFilterAndUpdate - Encrypt the filter and update of an update when encryption is enabled
+func (coll *ModelOf[T, ID]) encryptFilterAndUpdate(filter, update interface{}) (interface{}, interface{}, error) {
+	filter, err := coll.encryptFilter(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	update, err = coll.encryptUpdate(update)
+	return filter, update, err
+}
+
+// decryptValue - Decrypt the encrypted values of a decoded value when encryption is enabled
+func (coll *ModelOf[T, ID]) decryptValue(value interface{}) (interface{}, error) {
+	if coll.encryption == nil {
+		return value, nil
+	}
+	return coll.encrypter().decryptValue(value)
+}
+
+// decodeAll - Decode all documents of a cursor into results, decrypting them when encryption is enabled
+func (coll *ModelOf[T, ID]) decodeAll(cursor *mongo.Cursor, results interface{}) error {
+	if coll.encryption == nil {
+		return cursor.All(coll.ctx(), results)
+	}
+
+	var raws []bson.Raw
+	if err := cursor.All(coll.ctx(), &raws); err != nil {
+		return err
+	}
+	return coll.encrypter().decryptAll(raws, results)
+}
+
+// decodeResult - Decode a single result, decrypting it when encryption is enabled
+func (coll *ModelOf[T, ID]) decodeResult(res *mongo.SingleResult, result interface{}) error {
+	if coll.encryption == nil {
+		return res.Decode(result)
+	}
+
+	raw, err := res.Raw()
+	if err != nil {
+		return err
+	}
+	return coll.encrypter().decryptRaw(raw, result)
+}
+
+// RotateEncryptionKeys - Re-encrypt the values of documents matching filter
+// that were encrypted with another key than the current one, returns the
+// number of updated documents
+func (coll *ModelOf[T, ID]) RotateEncryptionKeys(filter interface{}) (int64, error) {
+	if coll.encryption == nil {
+		return 0, errors.New("gmongo: encryption is not enabled")
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	e := coll.encrypter()
+	current := e.provider.CurrentKeyID()
+
+	// documents with an encrypted value, the key is checked once decoded
+	encrypted := bson.A{}
+	for field := range e.fields {
+		encrypted = append(encrypted, bson.M{field: bson.M{"$type": "binData"}})
+	}
+	if len(encrypted) == 0 {
+		return 0, nil
+	}
+
+	cursor, err := coll.Native().Find(coll.ctx(), bson.M{"$and": bson.A{filter, bson.M{"$or": encrypted}}})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(coll.ctx())
+
+	var updated int64
+	for cursor.Next(coll.ctx()) {
+		var doc bson.M
+		if err = cursor.Decode(&doc); err != nil {
+			return updated, err
+		}
+
+		set := bson.M{}
+		for field, mode := range e.fields {
+			bin, ok := isEncrypted(doc[field])
+			if !ok || encryptedKeyID(bin) == current {
+				continue
+			}
+
+			value, err := e.decrypt(bin)
+			if err != nil {
+				return updated, err
+			}
+			if set[field], err = e.encryptWith(current, value, mode); err != nil {
+				return updated, err
+			}
+		}
+		if len(set) == 0 {
+			continue
+		}
+
+		// only update values that were not changed since they were read
+		match := bson.M{"_id": doc["_id"]}
+		for field := range set {
+			match[field] = doc[field]
+		}
+
+		res, err := coll.Native().UpdateOne(coll.ctx(), match, bson.M{"$set": set})
+		if err != nil {
+			return updated, err
+		}
+		updated += res.ModifiedCount
+	}
+
+	return updated, cursor.Err()
+}
@@ -0,0 +1,266 @@
+package gmongo
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Contact struct {
+	Phone string `bson:"phone" gmongo:"encrypt"`
+}
+
+type Customer struct {
+	ID      primitive.ObjectID `bson:"_id"`
+	Name    string             `bson:"name"`
+	Email   string             `bson:"email" gmongo:"encrypt=deterministic"`
+	Age     int                `bson:"age" gmongo:"encrypt"`
+	Contact `bson:",inline"`
+}
+
+func (c *Customer) GetID() primitive.ObjectID { return c.ID }
+
+func testKeyProvider(t *testing.T, current string, ids ...string) *LocalKeyProvider {
+	keys := map[string][]byte{}
+	for _, id := range ids {
+		// fixed keys, so providers created in different tests share them
+		keys[id] = []byte(("key " + id + "                                ")[:32])
+	}
+
+	provider, err := NewLocalKeyProvider(current, keys)
+	assert.Nil(t, err)
+	return provider
+}
+
+func Test_encryptedFields(t *testing.T) {
+	assert.Equal(t, map[string]EncryptMode{
+		"email": EncryptDeterministic,
+		"age":   EncryptRandom,
+		"phone": EncryptRandom,
+	}, encryptedFields(reflect.TypeOf(&Customer{})))
+}
+
+func TestFieldEncryption(t *testing.T) {
+	e := &fieldEncryption{provider: testKeyProvider(t, "k1", "k1"), fields: encryptedFields(reflect.TypeOf(&Customer{}))}
+
+	t.Run("Round trip", func(t *testing.T) {
+		for _, value := range []interface{}{"0800 123", int32(42), true, bson.D{{Key: "a", Value: "b"}}} {
+			encrypted, err := e.encrypt(value, EncryptRandom)
+			assert.Nil(t, err)
+
+			bin, ok := isEncrypted(encrypted)
+			assert.True(t, ok)
+			assert.Equal(t, "k1", encryptedKeyID(bin))
+
+			decrypted, err := e.decrypt(bin)
+			assert.Nil(t, err)
+			assert.Equal(t, value, decrypted)
+		}
+	})
+
+	t.Run("Random and deterministic modes", func(t *testing.T) {
+		a, _ := e.encrypt("john@example.com", EncryptRandom)
+		b, _ := e.encrypt("john@example.com", EncryptRandom)
+		assert.NotEqual(t, a, b)
+
+		a, _ = e.encrypt("john@example.com", EncryptDeterministic)
+		b, _ = e.encrypt("john@example.com", EncryptDeterministic)
+		c, _ := e.encrypt("jane@example.com", EncryptDeterministic)
+		assert.Equal(t, a, b)
+		assert.NotEqual(t, a, c)
+	})
+
+	t.Run("Tampered values are rejected", func(t *testing.T) {
+		encrypted, _ := e.encrypt("secret", EncryptRandom)
+		bin := encrypted.(primitive.Binary)
+		bin.Data[len(bin.Data)-1] ^= 1
+
+		_, err := e.decrypt(bin)
+		assert.True(t, errors.Is(err, ErrDecrypt))
+	})
+
+	t.Run("Retired keys decrypt", func(t *testing.T) {
+		old, _ := e.encrypt("secret", EncryptRandom)
+
+		rotated := &fieldEncryption{provider: testKeyProvider(t, "k2", "k1", "k2"), fields: e.fields}
+		decrypted, err := rotated.decryptValue(old)
+		assert.Nil(t, err)
+		assert.Equal(t, "secret", decrypted)
+
+		current, _ := rotated.encrypt("secret", EncryptRandom)
+		assert.Equal(t, "k2", encryptedKeyID(current.(primitive.Binary)))
+	})
+
+	t.Run("Documents and updates", func(t *testing.T) {
+		doc, err := e.encryptDocument(&Customer{Name: "John", Email: "john@example.com", Age: 30, Contact: Contact{Phone: "123"}})
+		assert.Nil(t, err)
+
+		// stored in the order of the struct fields
+		d := doc.(bson.D)
+		keys := []string{}
+		for _, elem := range d {
+			keys = append(keys, elem.Key)
+		}
+		assert.Equal(t, []string{"_id", "name", "email", "age", "phone"}, keys)
+
+		m := d.Map()
+		assert.Equal(t, "John", m["name"])
+		for _, field := range []string{"email", "age", "phone"} {
+			_, ok := isEncrypted(m[field])
+			assert.True(t, ok, field)
+		}
+
+		decrypted, err := e.decryptValue(d)
+		assert.Nil(t, err)
+		assert.Equal(t, "123", decrypted.(bson.D).Map()["phone"])
+
+		update, err := e.encryptUpdate(bson.D{
+			{Key: "$set", Value: bson.M{"phone": "456", "name": "Jack"}},
+			{Key: "$inc", Value: bson.M{"visits": 1}},
+		})
+		assert.Nil(t, err)
+		set := update.(bson.D)[0].Value.(bson.M)
+		_, ok := isEncrypted(set["phone"])
+		assert.True(t, ok)
+		assert.Equal(t, "Jack", set["name"])
+		assert.Equal(t, bson.M{"visits": 1}, update.(bson.D)[1].Value)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		rotated := &fieldEncryption{provider: testKeyProvider(t, "k2", "k1", "k2"), fields: e.fields}
+		k1, _ := e.encryptWith("k1", "a@example.com", EncryptDeterministic)
+		k2, _ := rotated.encryptWith("k2", "a@example.com", EncryptDeterministic)
+
+		filter, err := e.encryptFilter(bson.M{"email": "a@example.com", "name": "John", "phone": "123"})
+		assert.Nil(t, err)
+		// random fields can't be queried and are left as they are
+		assert.Equal(t, bson.M{"email": k1, "name": "John", "phone": "123"}, filter)
+
+		// values encrypted with any key match
+		filter, err = rotated.encryptFilter(bson.M{"$or": bson.A{bson.M{"email": bson.M{"$eq": "a@example.com"}}}})
+		assert.Nil(t, err)
+		assert.Equal(t, bson.M{"$or": bson.A{bson.M{"email": bson.M{"$in": bson.A{k1, k2}}}}}, filter)
+
+		filter, err = e.encryptFilter(bson.D{{Key: "email", Value: bson.M{"$nin": []string{"a@example.com"}}}})
+		assert.Nil(t, err)
+		assert.Equal(t, bson.D{{Key: "email", Value: bson.M{"$nin": bson.A{k1}}}}, filter)
+	})
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	key, err := GenerateKey()
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	content := `{"current": "k1", "keys": {"k1": "` + base64.StdEncoding.EncodeToString(key) + `"}}`
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0600))
+
+	provider, err := LoadKeyFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "k1", provider.CurrentKeyID())
+	assert.Equal(t, []string{"k1"}, provider.KeyIDs())
+
+	loaded, err := provider.Key("k1")
+	assert.Nil(t, err)
+	assert.Equal(t, key, loaded)
+
+	_, err = NewLocalKeyProvider("missing", map[string][]byte{"k1": key})
+	assert.NotNil(t, err)
+	_, err = NewLocalKeyProvider("k1", map[string][]byte{"k1": []byte("short")})
+	assert.NotNil(t, err)
+}
+
+func TestEncryption(t *testing.T) {
+	client := testConnectToDb()
+
+	CustomerModel := MakeModel[*Customer](client.Database, "customers")
+	CustomerModel.EnableEncryption(testKeyProvider(t, "k1", "k1"))
+	_, _ = CustomerModel.DeleteMany(bson.M{})
+
+	john := &Customer{ID: primitive.NewObjectID(), Name: "John", Email: "john@example.com", Age: 30, Contact: Contact{Phone: "123"}}
+	_, err := CustomerModel.InsertOne(john)
+	assert.Nil(t, err)
+
+	t.Run("Stored encrypted", func(t *testing.T) {
+		var raw bson.M
+		err := CustomerModel.Native().FindOne(context.TODO(), bson.M{"_id": john.ID}).Decode(&raw)
+		assert.Nil(t, err)
+		assert.Equal(t, "John", raw["name"])
+		_, ok := isEncrypted(raw["phone"])
+		assert.True(t, ok)
+	})
+
+	t.Run("Find decrypts and queries deterministic fields", func(t *testing.T) {
+		found, err := CustomerModel.FindOne(bson.M{"email": "john@example.com"})
+		assert.Nil(t, err)
+		assert.Equal(t, *john, *found)
+
+		customers, err := CustomerModel.Find(bson.M{"email": bson.M{"$in": bson.A{"john@example.com", "x@example.com"}}})
+		assert.Nil(t, err)
+		assert.Len(t, customers, 1)
+
+		page, err := CustomerModel.Paginate(1, 10, bson.M{"email": "john@example.com"})
+		assert.Nil(t, err)
+		assert.Equal(t, "123", page.Data.([]bson.M)[0]["phone"])
+	})
+
+	t.Run("Facets decrypt", func(t *testing.T) {
+		res, err := CustomerModel.Facets(bson.M{"email": "john@example.com"}, []string{"email"}, 1, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, res.Meta.Total)
+		assert.Equal(t, "123", res.Data.([]bson.M)[0]["phone"])
+		assert.Equal(t, []FacetCount{{Value: "john@example.com", Count: 1}}, res.Facets["email"])
+
+		page, err := CustomerModel.PaginateFacet(1, 10, []interface{}{bson.M{"$match": bson.M{"_id": john.ID}}})
+		assert.Nil(t, err)
+		assert.Equal(t, "john@example.com", page.Data.([]bson.M)[0]["email"])
+	})
+
+	t.Run("Updates encrypt", func(t *testing.T) {
+		_, err := CustomerModel.UpdateOne(bson.M{"email": "john@example.com"}, bson.M{"$set": bson.M{"phone": "456"}})
+		assert.Nil(t, err)
+
+		count, _ := CustomerModel.Native().CountDocuments(context.TODO(), bson.M{"phone": "456"})
+		assert.Equal(t, int64(0), count)
+
+		found, err := CustomerModel.FindOneById(john.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "456", found.Phone)
+	})
+
+	t.Run("Key rotation", func(t *testing.T) {
+		CustomerModel.EnableEncryption(testKeyProvider(t, "k2", "k1", "k2"))
+
+		// still found with the retired key
+		found, err := CustomerModel.FindOne(bson.M{"email": "john@example.com"})
+		assert.Nil(t, err)
+		assert.Equal(t, "456", found.Phone)
+
+		updated, err := CustomerModel.RotateEncryptionKeys(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), updated)
+
+		var raw bson.M
+		_ = CustomerModel.Native().FindOne(context.TODO(), bson.M{"_id": john.ID}).Decode(&raw)
+		bin, _ := isEncrypted(raw["email"])
+		assert.Equal(t, "k2", encryptedKeyID(bin))
+
+		// nothing left to rotate
+		updated, err = CustomerModel.RotateEncryptionKeys(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), updated)
+
+		CustomerModel.EnableEncryption(testKeyProvider(t, "k2", "k2"))
+		found, err = CustomerModel.FindOneById(john.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "john@example.com", found.Email)
+	})
+}
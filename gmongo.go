@@ -5,16 +5,21 @@ import (
 	"fmt"
 
 	"github.com/gookit/goutil/arrutil"
-	"github.com/samber/lo"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type ModelData interface {
+// ModelData - A document of a model keyed by an ObjectID
+type ModelData = ModelDataOf[primitive.ObjectID]
+
+// ModelDataOf - A document of a model keyed by an ID of type ID, e.g. a
+// string, an int64 or a UUID (binary subtype 4)
+type ModelDataOf[ID any] interface {
 	// GetID - A function that returns the ID of the model
-	GetID() primitive.ObjectID
+	GetID() ID
 }
 
 // Number constraint for allowed numeric types
@@ -22,20 +27,44 @@ type Number interface {
 	~int | ~int32 | ~int64 | ~float32 | ~float64
 }
 
-type Model[T ModelData] struct {
+// Model - A model of documents keyed by ObjectIDs
+type Model[T ModelData] = ModelOf[T, primitive.ObjectID]
+
+// ModelOf - A model of documents keyed by IDs of type ID, see MakeModelOf
+type ModelOf[T ModelDataOf[ID], ID any] struct {
 	CollectionName string
-	PublicFields   []string
-	Native         func() *mongo.Collection
-	txCtx          mongo.SessionContext
+	// PublicFields - bson paths picked by GetPublicFields and ProjectPublicFields,
+	// nested fields are picked with dotted paths such as "profile.avatar"
+	PublicFields []string
+	// PublicFieldsTag - Tag naming the keys of GetPublicFields and GetProfileFields, "bson" (default) or "json"
+	PublicFieldsTag string
+	// Profiles - bson paths visible to each visibility profile, see SetProfile
+	Profiles   map[string][]string
+	Native     func() *mongo.Collection
+	boundCtx   context.Context
+	audit      *AuditOptions
+	history    *HistoryOptions
+	sequences  *Client
+	client     *Client
+	encryption *fieldEncryption
+}
+
+// ctx returns the context this Model is bound to (via WithTx or WithContext),
+// otherwise context.TODO(). All CRUD methods route through this.
+func (coll *ModelOf[T, ID]) ctx() context.Context {
+	if coll.boundCtx != nil {
+		return coll.boundCtx
+	}
+	return context.TODO()
 }
 
-// ctx returns the session context if this Model is bound to a transaction
-// (via WithTx), otherwise context.TODO(). All CRUD methods route through this.
-func (coll *Model[T]) ctx() context.Context {
-	if coll.txCtx != nil {
-		return coll.txCtx
+// registry - The registry of the client the model is linked to, see
+// MakeClientModel, bson.DefaultRegistry if none
+func (coll *ModelOf[T, ID]) registry() *bsoncodec.Registry {
+	if coll.client != nil && coll.client.Registry != nil {
+		return coll.client.Registry
 	}
-	return context.TODO()
+	return bson.DefaultRegistry
 }
 
 // WithTx returns a copy of the model bound to the given transaction. All
@@ -43,16 +72,43 @@ func (coll *Model[T]) ctx() context.Context {
 //
 //	accounts := AccountModel.WithTx(tx)
 //	accounts.UpdateOne(...)
-func (coll *Model[T]) WithTx(tx *Tx) *Model[T] {
+func (coll *ModelOf[T, ID]) WithTx(tx *Tx) *ModelOf[T, ID] {
+	clone := *coll
+	clone.boundCtx = tx.sc
+	return &clone
+}
+
+// WithSession returns a copy of the model bound to a causally consistent
+// session, see Client.Session.
+func (coll *ModelOf[T, ID]) WithSession(s *Session) *ModelOf[T, ID] {
+	clone := *coll
+	clone.boundCtx = s.sc
+	return &clone
+}
+
+// WithContext returns a copy of the model bound to ctx, used for deadlines and
+// cancellation of all its operations. If ctx belongs to a transaction (e.g. it
+// was derived from tx.Context()), the operations are enrolled in it.
+//
+//	func (s *Service) Deposit(ctx context.Context, id primitive.ObjectID, amount int) error {
+//		_, err := AccountModel.WithContext(ctx).UpdateOne(...)
+//		return err
+//	}
+func (coll *ModelOf[T, ID]) WithContext(ctx context.Context) *ModelOf[T, ID] {
 	clone := *coll
-	clone.txCtx = tx.sc
+	clone.boundCtx = ctx
 	return &clone
 }
 
 // CreateModel - Create a new model with default values
 // Note: Created model will not have a collection and will throw an error if `.Native()` is called
 func CreateModel[T ModelData](collectionName string) *Model[T] {
-	return &Model[T]{
+	return CreateModelOf[T, primitive.ObjectID](collectionName)
+}
+
+// CreateModelOf - CreateModel for documents keyed by IDs of type ID
+func CreateModelOf[T ModelDataOf[ID], ID any](collectionName string) *ModelOf[T, ID] {
+	return &ModelOf[T, ID]{
 		CollectionName: collectionName,
 		PublicFields:   []string{},
 		Native: func() *mongo.Collection {
@@ -64,6 +120,14 @@ func CreateModel[T ModelData](collectionName string) *Model[T] {
 
 // MakeModel - Create a new model with a database connection
 func MakeModel[T ModelData](db *mongo.Database, collectionName string) Model[T] {
+	return MakeModelOf[T, primitive.ObjectID](db, collectionName)
+}
+
+// MakeModelOf - MakeModel for documents keyed by IDs of type ID
+//
+//	InvoiceModel := gmongo.MakeModelOf[*Invoice, string](db, "invoices")
+//	invoice, err := InvoiceModel.FindOneById("INV-1")
+func MakeModelOf[T ModelDataOf[ID], ID any](db *mongo.Database, collectionName string) ModelOf[T, ID] {
 	// check if collection name is empty
 	if collectionName == "" {
 		panic("Collection name is empty")
@@ -71,7 +135,7 @@ func MakeModel[T ModelData](db *mongo.Database, collectionName string) Model[T]
 
 	collection := db.Collection(collectionName)
 
-	return Model[T]{
+	return ModelOf[T, ID]{
 		CollectionName: collectionName,
 		PublicFields:   []string{},
 		Native: func() *mongo.Collection {
@@ -80,8 +144,22 @@ func MakeModel[T ModelData](db *mongo.Database, collectionName string) Model[T]
 	}
 }
 
+// MakeClientModel - MakeModel on the database of client. Documents the model
+// converts itself (audit, history, encryption, aggregation results) use the
+// registry of the client.
+func MakeClientModel[T ModelData](client *Client, collectionName string) Model[T] {
+	return MakeClientModelOf[T, primitive.ObjectID](client, collectionName)
+}
+
+// MakeClientModelOf - MakeClientModel for documents keyed by IDs of type ID
+func MakeClientModelOf[T ModelDataOf[ID], ID any](client *Client, collectionName string) ModelOf[T, ID] {
+	model := MakeModelOf[T, ID](client.Database, collectionName)
+	model.client = client
+	return model
+}
+
 // LinkModel - Link model to a database
-func LinkModel[T ModelData](model *Model[T], db *mongo.Database) {
+func LinkModel[T ModelDataOf[ID], ID any](model *ModelOf[T, ID], db *mongo.Database) {
 	// check if collection name is empty
 	if model.CollectionName == "" {
 		panic("Collection name is empty")
@@ -95,14 +173,24 @@ func LinkModel[T ModelData](model *Model[T], db *mongo.Database) {
 	}
 }
 
+// LinkClientModel - Link model to the database of client, see MakeClientModel
+func LinkClientModel[T ModelDataOf[ID], ID any](model *ModelOf[T, ID], client *Client) {
+	LinkModel(model, client.Database)
+	model.client = client
+}
+
 // FindOneAs - Find one document and decode it into a different struct
-func (coll *Model[T]) FindOneAs(result interface{}, filter interface{}, opts ...*options.FindOneOptions) error {
-	err := coll.Native().FindOne(coll.ctx(), filter, opts...).Decode(result)
-	return err
+func (coll *ModelOf[T, ID]) FindOneAs(result interface{}, filter interface{}, opts ...*options.FindOneOptions) error {
+	filter, err := coll.encryptFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	return coll.decodeResult(coll.Native().FindOne(coll.ctx(), filter, opts...), result)
 }
 
 // FindOne - Find one document and decode it into the same struct
-func (coll *Model[T]) FindOne(filter interface{}, opts ...*options.FindOneOptions) (T, error) {
+func (coll *ModelOf[T, ID]) FindOne(filter interface{}, opts ...*options.FindOneOptions) (T, error) {
 	var result T
 
 	err := coll.FindOneAs(&result, filter, opts...)
@@ -114,41 +202,233 @@ func (coll *Model[T]) FindOne(filter interface{}, opts ...*options.FindOneOption
 }
 
 // FindOneById - Find one document by ID
-func (coll *Model[T]) FindOneById(id primitive.ObjectID, opts ...*options.FindOneOptions) (T, error) {
+func (coll *ModelOf[T, ID]) FindOneById(id ID, opts ...*options.FindOneOptions) (T, error) {
 	return coll.FindOne(bson.M{"_id": id}, opts...)
 }
 
+// FindByIds - Find the documents with the given IDs
+//
+//	invoices, err := InvoiceModel.FindByIds([]string{"INV-1", "INV-2"})
+func (coll *ModelOf[T, ID]) FindByIds(ids []ID, opts ...*options.FindOptions) ([]T, error) {
+	return coll.Find(bson.M{"_id": bson.M{"$in": ids}}, opts...)
+}
+
 // DeleteOne Delete - Delete model from database
-func (coll *Model[T]) DeleteOne(filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+func (coll *ModelOf[T, ID]) DeleteOne(filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	filter, err := coll.encryptFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if coll.tracked() {
+		return coll.trackedDeleteOne(filter, opts...)
+	}
 	return coll.Native().DeleteOne(coll.ctx(), filter, opts...)
 }
 
 // UpdateOne - Update model in database
-func (coll *Model[T]) UpdateOne(filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+func (coll *ModelOf[T, ID]) UpdateOne(filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	filter, update, err := coll.encryptFilterAndUpdate(filter, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if coll.tracked() {
+		return coll.trackedUpdateOne(filter, update, opts...)
+	}
 	return coll.Native().UpdateOne(coll.ctx(), filter, update, opts...)
 }
 
 // InsertOne - Insert a single document
-func (coll *Model[T]) InsertOne(doc T, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
-	return coll.Native().InsertOne(coll.ctx(), doc, opts...)
+func (coll *ModelOf[T, ID]) InsertOne(doc T, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	if coll.sequences != nil {
+		if err := coll.sequences.fillSequences(coll.ctx(), doc); err != nil {
+			return nil, err
+		}
+	}
+	payload, err := coll.encryptDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if coll.tracked() {
+		return coll.trackedInsertOne(payload, opts...)
+	}
+	return coll.Native().InsertOne(coll.ctx(), payload, opts...)
 }
 
 // InsertMany - Insert multiple documents
-func (coll *Model[T]) InsertMany(docs []T, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+func (coll *ModelOf[T, ID]) InsertMany(docs []T, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
 	payload := make([]interface{}, len(docs))
 	for i, d := range docs {
-		payload[i] = d
+		if coll.sequences != nil {
+			if err := coll.sequences.fillSequences(coll.ctx(), d); err != nil {
+				return nil, err
+			}
+		}
+
+		var err error
+		if payload[i], err = coll.encryptDocument(d); err != nil {
+			return nil, err
+		}
+	}
+
+	if coll.tracked() {
+		return coll.trackedInsertMany(payload, opts...)
 	}
 	return coll.Native().InsertMany(coll.ctx(), payload, opts...)
 }
 
+// UpdateMany - Update all documents matching the filter
+func (coll *ModelOf[T, ID]) UpdateMany(filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	filter, update, err := coll.encryptFilterAndUpdate(filter, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if coll.tracked() {
+		return coll.trackedUpdateMany(filter, update, opts...)
+	}
+	return coll.Native().UpdateMany(coll.ctx(), filter, update, opts...)
+}
+
+// DeleteMany - Delete all documents matching the filter
+func (coll *ModelOf[T, ID]) DeleteMany(filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	filter, err := coll.encryptFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if coll.tracked() {
+		return coll.trackedDeleteMany(filter, opts...)
+	}
+	return coll.Native().DeleteMany(coll.ctx(), filter, opts...)
+}
+
+// ReplaceOne - Replace a single document
+func (coll *ModelOf[T, ID]) ReplaceOne(filter interface{}, doc T, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	filter, err := coll.encryptFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	replacement, err := coll.encryptDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if coll.tracked() {
+		return coll.trackedReplaceOne(filter, replacement, opts...)
+	}
+	return coll.Native().ReplaceOne(coll.ctx(), filter, replacement, opts...)
+}
+
+// Upsert - Update a single document, inserting it if no document matches the filter
+func (coll *ModelOf[T, ID]) Upsert(filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	opts = append(opts, options.Update().SetUpsert(true))
+	return coll.UpdateOne(filter, update, opts...)
+}
+
+// FindOneAndUpdate - Atomically update one document and decode it.
+//
+// By default, the document is returned as it was before the update,
+// use options.FindOneAndUpdate().SetReturnDocument(options.After) to get the updated document.
+//
+//	job, err := JobModel.FindOneAndUpdate(
+//		bson.M{"status": "pending"},
+//		bson.M{"$set": bson.M{"status": "running"}},
+//		options.FindOneAndUpdate().SetReturnDocument(options.After),
+//	)
+func (coll *ModelOf[T, ID]) FindOneAndUpdate(filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) (T, error) {
+	var result T
+	filter, update, err := coll.encryptFilterAndUpdate(filter, update)
+	if err != nil {
+		return result, err
+	}
+
+	if coll.tracked() {
+		opt := options.MergeFindOneAndUpdateOptions(opts...)
+		if opt.Projection != nil {
+			return result, errTrackedProjection
+		}
+		upsert := opt.Upsert != nil && *opt.Upsert
+		returnAfter := opt.ReturnDocument != nil && *opt.ReturnDocument == options.After
+
+		err = coll.trackedFindOneAnd(filter, upsert, returnAfter, &result, func(target interface{}, upsert, after bool) *mongo.SingleResult {
+			return coll.Native().FindOneAndUpdate(coll.ctx(), target, update, opt,
+				options.FindOneAndUpdate().SetUpsert(upsert).SetReturnDocument(returnDocument(after)))
+		})
+		return result, err
+	}
+
+	err = coll.decodeResult(coll.Native().FindOneAndUpdate(coll.ctx(), filter, update, opts...), &result)
+	return result, err
+}
+
+// FindOneAndReplace - Atomically replace one document and decode it.
+//
+// Like FindOneAndUpdate, the document before the replacement is returned unless
+// options.After is set.
+func (coll *ModelOf[T, ID]) FindOneAndReplace(filter interface{}, doc T, opts ...*options.FindOneAndReplaceOptions) (T, error) {
+	var result T
+	filter, err := coll.encryptFilter(filter)
+	if err != nil {
+		return result, err
+	}
+	replacement, err := coll.encryptDocument(doc)
+	if err != nil {
+		return result, err
+	}
+
+	if coll.tracked() {
+		opt := options.MergeFindOneAndReplaceOptions(opts...)
+		if opt.Projection != nil {
+			return result, errTrackedProjection
+		}
+		upsert := opt.Upsert != nil && *opt.Upsert
+		returnAfter := opt.ReturnDocument != nil && *opt.ReturnDocument == options.After
+
+		err = coll.trackedFindOneAnd(filter, upsert, returnAfter, &result, func(target interface{}, upsert, after bool) *mongo.SingleResult {
+			return coll.Native().FindOneAndReplace(coll.ctx(), target, replacement, opt,
+				options.FindOneAndReplace().SetUpsert(upsert).SetReturnDocument(returnDocument(after)))
+		})
+		return result, err
+	}
+
+	err = coll.decodeResult(coll.Native().FindOneAndReplace(coll.ctx(), filter, replacement, opts...), &result)
+	return result, err
+}
+
+// FindOneAndDelete - Atomically delete one document and return it
+func (coll *ModelOf[T, ID]) FindOneAndDelete(filter interface{}, opts ...*options.FindOneAndDeleteOptions) (T, error) {
+	var result T
+	filter, err := coll.encryptFilter(filter)
+	if err != nil {
+		return result, err
+	}
+
+	if coll.tracked() {
+		if opt := options.MergeFindOneAndDeleteOptions(opts...); opt.Projection != nil {
+			return result, errTrackedProjection
+		}
+		return result, coll.trackedFindOneAndDelete(filter, &result, opts...)
+	}
+
+	err = coll.decodeResult(coll.Native().FindOneAndDelete(coll.ctx(), filter, opts...), &result)
+	return result, err
+}
+
 // Count - Count documents in database
-func (coll *Model[T]) Count(filter interface{}, opts ...*options.CountOptions) (int64, error) {
+func (coll *ModelOf[T, ID]) Count(filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	filter, err := coll.encryptFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+
 	return coll.Native().CountDocuments(coll.ctx(), filter, opts...)
 }
 
 // Exists - Check if document exists
-func (coll *Model[T]) Exists(filter interface{}) (bool, error) {
+func (coll *ModelOf[T, ID]) Exists(filter interface{}) (bool, error) {
 	var res bson.M
 
 	// Project only ID so that mongodb doesn't have to read disk.
@@ -165,9 +445,9 @@ func (coll *Model[T]) Exists(filter interface{}) (bool, error) {
 }
 
 // CountAggregate - Count documents in database using an aggregation pipeline
-func (coll *Model[T]) CountAggregate(pipeline []interface{}, opts ...*options.AggregateOptions) (int64, error) {
+func (coll *ModelOf[T, ID]) CountAggregate(pipeline []interface{}, opts ...*options.AggregateOptions) (int64, error) {
 	// Append a $count stage to the pipeline
-	countPipeline := append(pipeline, bson.D{{"$count", "count"}})
+	countPipeline := append(pipeline, bson.D{{Key: "$count", Value: "count"}})
 
 	// Run the aggregation
 	ctx := coll.ctx()
@@ -197,17 +477,17 @@ func (coll *Model[T]) CountAggregate(pipeline []interface{}, opts ...*options.Ag
 }
 
 // ProjectPublicFields - Project public fields
-func (coll *Model[T]) ProjectPublicFields() bson.M {
+func (coll *ModelOf[T, ID]) ProjectPublicFields() bson.M {
 	return Projection.OmitIdAndPick(coll.PublicFields)
 }
 
 // ProjectPublicFieldsAnd - Project public fields including some keys
-func (coll *Model[T]) ProjectPublicFieldsAnd(keys []string) bson.M {
+func (coll *ModelOf[T, ID]) ProjectPublicFieldsAnd(keys []string) bson.M {
 	return Projection.OmitIdAndPick(append(coll.PublicFields, keys...))
 }
 
 // ProjectPublicFieldsWithout - Project public fields excluding some keys
-func (coll *Model[T]) ProjectPublicFieldsWithout(keys []string) bson.M {
+func (coll *ModelOf[T, ID]) ProjectPublicFieldsWithout(keys []string) bson.M {
 	var newKeys []string
 
 	for _, key := range coll.PublicFields {
@@ -221,23 +501,22 @@ func (coll *Model[T]) ProjectPublicFieldsWithout(keys []string) bson.M {
 }
 
 // GetPublicFields - Get public fields
-func (coll *Model[T]) GetPublicFields(model ModelData) bson.M {
-	modelMap := structToMapWithTags(model, "bson")
-	return lo.PickByKeys(modelMap, coll.PublicFields)
+func (coll *ModelOf[T, ID]) GetPublicFields(model interface{}) bson.M {
+	return pickPaths(model, coll.PublicFields, coll.publicKeyTag())
 }
 
 // GetPublicFieldsAnd - Get public fields
-func (coll *Model[T]) GetPublicFieldsAnd(model ModelData, interceptor func(data bson.M) bson.M) bson.M {
+func (coll *ModelOf[T, ID]) GetPublicFieldsAnd(model interface{}, interceptor func(data bson.M) bson.M) bson.M {
 	return interceptor(coll.GetPublicFields(model))
 }
 
 // Helpers - get model helper
-func (coll *Model[T]) Helpers(model T) *ModelHelper[T] {
+func (coll *ModelOf[T, ID]) Helpers(model T) *ModelHelperOf[T, ID] {
 	return GetModelHelper(coll, &model)
 }
 
 // Aggregate - Aggregate
-func (coll *Model[T]) Aggregate(pipeline interface{}, opts ...*options.AggregateOptions) ([]bson.M, error) {
+func (coll *ModelOf[T, ID]) Aggregate(pipeline interface{}, opts ...*options.AggregateOptions) ([]bson.M, error) {
 	var results = make([]bson.M, 0)
 	ctx := coll.ctx()
 	cursor, err := coll.Native().Aggregate(ctx, pipeline, opts...)
@@ -253,7 +532,7 @@ func (coll *Model[T]) Aggregate(pipeline interface{}, opts ...*options.Aggregate
 }
 
 // AggregateAs - Aggregate with custom
-func (coll *Model[T]) AggregateAs(result interface{}, pipeline interface{}, opts ...*options.AggregateOptions) error {
+func (coll *ModelOf[T, ID]) AggregateAs(result interface{}, pipeline interface{}, opts ...*options.AggregateOptions) error {
 	ctx := coll.ctx()
 	cursor, err := coll.Native().Aggregate(ctx, pipeline, opts...)
 	if err != nil {
@@ -268,15 +547,9 @@ func (coll *Model[T]) AggregateAs(result interface{}, pipeline interface{}, opts
 }
 
 // Find - Find documents
-func (coll *Model[T]) Find(filter interface{}, opts ...*options.FindOptions) ([]T, error) {
+func (coll *ModelOf[T, ID]) Find(filter interface{}, opts ...*options.FindOptions) ([]T, error) {
 	var results = make([]T, 0)
-	ctx := coll.ctx()
-	cursor, err := coll.Native().Find(ctx, filter, opts...)
-	if err != nil {
-		return results, err
-	}
-
-	if err = cursor.All(ctx, &results); err != nil {
+	if err := coll.FindAs(&results, filter, opts...); err != nil {
 		return results, err
 	}
 
@@ -284,22 +557,22 @@ func (coll *Model[T]) Find(filter interface{}, opts ...*options.FindOptions) ([]
 }
 
 // FindAs - Find documents and decode it into a different struct
-func (coll *Model[T]) FindAs(result interface{}, filter interface{}, opts ...*options.FindOptions) error {
-	ctx := coll.ctx()
-	cursor, err := coll.Native().Find(ctx, filter, opts...)
+func (coll *ModelOf[T, ID]) FindAs(result interface{}, filter interface{}, opts ...*options.FindOptions) error {
+	filter, err := coll.encryptFilter(filter)
 	if err != nil {
 		return err
 	}
 
-	if err = cursor.All(ctx, result); err != nil {
+	cursor, err := coll.Native().Find(coll.ctx(), filter, opts...)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return coll.decodeAll(cursor, result)
 }
 
 // FindOneAsHelper - Find one document and decode it into the same struct
-func (coll *Model[T]) FindOneAsHelper(filter interface{}, opts ...*options.FindOneOptions) (*ModelHelper[T], error) {
+func (coll *ModelOf[T, ID]) FindOneAsHelper(filter interface{}, opts ...*options.FindOneOptions) (*ModelHelperOf[T, ID], error) {
 	result, err := coll.FindOne(filter, opts...)
 
 	if err != nil {
@@ -321,7 +594,7 @@ func (coll *Model[T]) FindOneAsHelper(filter interface{}, opts ...*options.FindO
 //
 //	sum, _ := UserModel.SumMany(int(0), []string{"credit", "debit"})
 //	// sum will be {credit: 300, debit: 700}
-func (coll *Model[T]) SumMany(resType interface{}, keys []string, filter interface{}) (bson.M, error) {
+func (coll *ModelOf[T, ID]) SumMany(resType interface{}, keys []string, filter interface{}) (bson.M, error) {
 	switch resType.(type) {
 	case int:
 		return SumMany[int](coll, keys, filter)
@@ -333,6 +606,8 @@ func (coll *Model[T]) SumMany(resType interface{}, keys []string, filter interfa
 		return SumMany[float32](coll, keys, filter)
 	case float64:
 		return SumMany[float64](coll, keys, filter)
+	case primitive.Decimal128:
+		return SumManyDecimal(coll, keys, filter)
 	default:
 		return bson.M{}, fmt.Errorf("unsupported type")
 	}
@@ -349,13 +624,65 @@ func (coll *Model[T]) SumMany(resType interface{}, keys []string, filter interfa
 // ]
 // sum, _ := UserModel.Sum(float64(0), "credit", nil)
 // // sum will be 300
-func (coll *Model[T]) Sum(key string, filter interface{}) (int, error) {
+func (coll *ModelOf[T, ID]) Sum(key string, filter interface{}) (int, error) {
 	return Sum[int](coll, key, filter)
 }
 
 // SumFloat - Sum documents and return float
 //
 // Same as Sum but returns float
-func (coll *Model[T]) SumFloat(key string, filter interface{}) (float64, error) {
+func (coll *ModelOf[T, ID]) SumFloat(key string, filter interface{}) (float64, error) {
 	return Sum[float64](coll, key, filter)
 }
+
+// SumDecimal - Sum documents and return a Decimal128
+//
+// Same as Sum but sums without losing precision, see SumManyDecimal
+func (coll *ModelOf[T, ID]) SumDecimal(key string, filter interface{}) (primitive.Decimal128, error) {
+	return SumDecimal(coll, key, filter)
+}
+
+// Avg - Average of a key
+func (coll *ModelOf[T, ID]) Avg(key string, filter interface{}) (float64, error) {
+	return Avg(coll, key, filter)
+}
+
+// Min - Minimum value of a key
+func (coll *ModelOf[T, ID]) Min(key string, filter interface{}) (float64, error) {
+	return Min[float64](coll, key, filter)
+}
+
+// Max - Maximum value of a key
+func (coll *ModelOf[T, ID]) Max(key string, filter interface{}) (float64, error) {
+	return Max[float64](coll, key, filter)
+}
+
+// AvgDecimal - Same as Avg but returns a Decimal128, see StatsDecimal
+func (coll *ModelOf[T, ID]) AvgDecimal(key string, filter interface{}) (primitive.Decimal128, error) {
+	return AvgDecimal(coll, key, filter)
+}
+
+// MinDecimal - Same as Min but returns a Decimal128
+func (coll *ModelOf[T, ID]) MinDecimal(key string, filter interface{}) (primitive.Decimal128, error) {
+	return MinDecimal(coll, key, filter)
+}
+
+// MaxDecimal - Same as Max but returns a Decimal128
+func (coll *ModelOf[T, ID]) MaxDecimal(key string, filter interface{}) (primitive.Decimal128, error) {
+	return MaxDecimal(coll, key, filter)
+}
+
+// Stats - Compute count, sum, avg, min and max of many keys, see Stats
+func (coll *ModelOf[T, ID]) Stats(keys []string, filter interface{}) (map[string]Aggregates[float64], error) {
+	return Stats[float64](coll, keys, filter)
+}
+
+// StatsDecimal - Same as Stats but without losing precision, see StatsDecimal
+func (coll *ModelOf[T, ID]) StatsDecimal(keys []string, filter interface{}) (map[string]DecimalAggregates, error) {
+	return StatsDecimal(coll, keys, filter)
+}
+
+// TimeSeries - Sum keys per time bucket, see TimeSeries
+func (coll *ModelOf[T, ID]) TimeSeries(opt TimeSeriesOptions) ([]TimeBucket[float64], error) {
+	return TimeSeries[float64](coll, opt)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson"
@@ -40,6 +41,109 @@ type StampedUser struct {
 
 func (u *StampedUser) GetID() primitive.ObjectID { return u.ID }
 
+type Invoice struct {
+	ID     string `bson:"_id"`
+	Amount int    `bson:"amount"`
+}
+
+func (i *Invoice) GetID() string { return i.ID }
+
+type Counter struct {
+	ID    int64 `bson:"_id"`
+	Value int   `bson:"value"`
+}
+
+func (c *Counter) GetID() int64 { return c.ID }
+
+type Device struct {
+	ID   primitive.Binary `bson:"_id"`
+	Name string           `bson:"name"`
+}
+
+func (d *Device) GetID() primitive.Binary { return d.ID }
+
+func Test_ModelHelperOf_GetID(t *testing.T) {
+	oid := primitive.NewObjectID()
+	uuid := primitive.Binary{Subtype: 4, Data: make([]byte, 16)}
+
+	assert.Equal(t, oid, CreateModel[*User]("users").Helpers(&User{ID: oid}).GetID())
+	assert.Equal(t, "INV-1", CreateModelOf[*Invoice, string]("invoices").Helpers(&Invoice{ID: "INV-1"}).GetID())
+	assert.Equal(t, int64(7), CreateModelOf[*Counter, int64]("counters").Helpers(&Counter{ID: 7}).GetID())
+	assert.Equal(t, uuid, CreateModelOf[*Device, primitive.Binary]("devices").Helpers(&Device{ID: uuid}).GetID())
+
+	// nil data has the zero ID instead of panicking in GetID
+	assert.Equal(t, "", CreateModelOf[*Invoice, string]("invoices").Helpers(nil).GetID())
+	assert.Equal(t, primitive.NilObjectID, (ModelHelper[*User]{}).GetID())
+}
+
+func TestModel_NonObjectIDKeys(t *testing.T) {
+	client := testConnectToDb()
+
+	t.Run("String", func(t *testing.T) {
+		InvoiceModel := MakeModelOf[*Invoice, string](client.Database, "invoices")
+		_, _ = InvoiceModel.DeleteMany(bson.M{})
+
+		id := NewUUid()
+		_, err := InvoiceModel.InsertMany([]*Invoice{{ID: id, Amount: 10}, {ID: "other", Amount: 20}})
+		assert.Nil(t, err)
+
+		invoice, err := InvoiceModel.FindOneById(id)
+		assert.Nil(t, err)
+		assert.Equal(t, 10, invoice.Amount)
+
+		invoices, err := InvoiceModel.FindByIds([]string{id, "other"})
+		assert.Nil(t, err)
+		assert.Len(t, invoices, 2)
+
+		helper := InvoiceModel.Helpers(invoice)
+		_, err = helper.Update(bson.M{"amount": 15})
+		assert.Nil(t, err)
+		invoice, _ = InvoiceModel.FindOneById(id)
+		assert.Equal(t, 15, invoice.Amount)
+
+		deleted, err := helper.Delete()
+		assert.Nil(t, err)
+		assert.EqualValues(t, 1, deleted.DeletedCount)
+	})
+
+	t.Run("Int", func(t *testing.T) {
+		CounterModel := MakeModelOf[*Counter, int64](client.Database, "counters_test")
+		_, _ = CounterModel.DeleteMany(bson.M{})
+
+		_, err := CounterModel.InsertMany([]*Counter{{ID: 1}, {ID: 2}, {ID: 3}})
+		assert.Nil(t, err)
+
+		counters, err := CounterModel.FindByIds([]int64{1, 3})
+		assert.Nil(t, err)
+		assert.Len(t, counters, 2)
+
+		counter, err := CounterModel.FindOneById(int64(2))
+		assert.Nil(t, err)
+		_, err = CounterModel.Helpers(counter).Update(bson.M{"value": 5})
+		assert.Nil(t, err)
+
+		counter, _ = CounterModel.FindOneById(int64(2))
+		assert.Equal(t, 5, counter.Value)
+	})
+
+	t.Run("UUID", func(t *testing.T) {
+		DeviceModel := MakeModelOf[*Device, primitive.Binary](client.Database, "devices")
+		_, _ = DeviceModel.DeleteMany(bson.M{})
+
+		id := primitive.Binary{Subtype: 4, Data: []byte("0123456789abcdef")}
+		_, err := DeviceModel.InsertOne(&Device{ID: id, Name: "phone"})
+		assert.Nil(t, err)
+
+		device, err := DeviceModel.FindOneById(id)
+		assert.Nil(t, err)
+		assert.Equal(t, "phone", device.Name)
+
+		deleted, err := DeviceModel.Helpers(device).Delete()
+		assert.Nil(t, err)
+		assert.EqualValues(t, 1, deleted.DeletedCount)
+	})
+}
+
 func TestModel_GetPublicFields_Inline(t *testing.T) {
 	m := CreateModel[*StampedUser]("stamped")
 	m.PublicFields = []string{"_id", "createdAt", "name"}
@@ -192,6 +296,141 @@ func TestModel(t *testing.T) {
 		newUser = CreateTestUser()
 	})
 
+	// Test `UpdateMany`
+	t.Run("Update Many", func(t *testing.T) {
+		CreateMultipleTestUsers(3)
+
+		updated, err := UserModel.UpdateMany(bson.M{}, bson.M{"$set": bson.M{"verified": false}})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.EqualValues(t, updated.ModifiedCount, 3)
+
+		count, _ := UserModel.Count(bson.M{"verified": false})
+		assert.EqualValues(t, count, 3)
+
+		newUser = CreateTestUser()
+	})
+
+	// Test `DeleteMany`
+	t.Run("Delete Many", func(t *testing.T) {
+		CreateMultipleTestUsers(3)
+
+		deleted, err := UserModel.DeleteMany(bson.M{"name": "John"})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.EqualValues(t, deleted.DeletedCount, 3)
+
+		newUser = CreateTestUser()
+	})
+
+	// Test `ReplaceOne`
+	t.Run("Replace One", func(t *testing.T) {
+		newUser = CreateTestUser()
+
+		replaced, err := UserModel.ReplaceOne(bson.M{"_id": newUser.ID}, &User{
+			ID:   newUser.ID,
+			Name: "Jane",
+			Age:  30,
+		})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.EqualValues(t, replaced.ModifiedCount, 1)
+
+		user, _ := UserModel.FindOneById(newUser.ID)
+		assert.Equal(t, user, &User{ID: newUser.ID, Name: "Jane", Age: 30})
+
+		newUser = CreateTestUser()
+	})
+
+	// Test `Upsert`
+	t.Run("Upsert", func(t *testing.T) {
+		DeleteAllUsers()
+
+		id := NewId()
+		upserted, err := UserModel.Upsert(bson.M{"_id": id}, bson.M{"$set": bson.M{"name": "Jack", "age": 25}})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, upserted.UpsertedID, id)
+
+		upserted, err = UserModel.Upsert(bson.M{"_id": id}, bson.M{"$inc": bson.M{"age": 1}})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.EqualValues(t, upserted.ModifiedCount, 1)
+
+		user, _ := UserModel.FindOneById(id)
+		assert.Equal(t, user.Age, 26)
+
+		newUser = CreateTestUser()
+	})
+
+	// Test `FindOneAndUpdate`
+	t.Run("Find One And Update", func(t *testing.T) {
+		newUser = CreateTestUser()
+
+		before, err := UserModel.FindOneAndUpdate(bson.M{"_id": newUser.ID}, bson.M{"$inc": bson.M{"age": 1}})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, before.Age, 20)
+
+		after, err := UserModel.FindOneAndUpdate(
+			bson.M{"_id": newUser.ID},
+			bson.M{"$inc": bson.M{"age": 1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		)
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, after.Age, 22)
+
+		_, err = UserModel.FindOneAndUpdate(bson.M{"_id": NewId()}, bson.M{"$inc": bson.M{"age": 1}})
+		assert.True(t, IsNoDocumentsError(err))
+	})
+
+	// Test `FindOneAndReplace`
+	t.Run("Find One And Replace", func(t *testing.T) {
+		newUser = CreateTestUser()
+
+		before, err := UserModel.FindOneAndReplace(bson.M{"_id": newUser.ID}, &User{ID: newUser.ID, Name: "Jane"})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, before.Name, "John")
+
+		user, _ := UserModel.FindOneById(newUser.ID)
+		assert.Equal(t, user.Name, "Jane")
+	})
+
+	// Test `FindOneAndDelete`
+	t.Run("Find One And Delete", func(t *testing.T) {
+		newUser = CreateTestUser()
+
+		deleted, err := UserModel.FindOneAndDelete(bson.M{"_id": newUser.ID})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, deleted.ID, newUser.ID)
+
+		exists, _ := UserModel.Exists(bson.M{"_id": newUser.ID})
+		assert.False(t, exists)
+
+		newUser = CreateTestUser()
+	})
+
 	// Test `Count`
 	t.Run("Count", func(t *testing.T) {
 		count, err := UserModel.Count(bson.M{})
@@ -284,6 +523,126 @@ func TestModel(t *testing.T) {
 		assert.EqualValues(t, sum, 80)
 	})
 
+	// Test `Stats`
+	t.Run("Stats", func(t *testing.T) {
+		newUser = CreateTestUser()
+		_, _ = UserModel.InsertOne(&User{ID: NewId(), Name: "Doe", Age: 30})
+
+		stats, err := UserModel.Stats([]string{"age"}, nil)
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, stats["age"], Aggregates[float64]{Count: 2, Sum: 50, Avg: 25, Min: 20, Max: 30})
+
+		avg, _ := UserModel.Avg("age", nil)
+		assert.Equal(t, avg, float64(25))
+
+		minAge, _ := Min[int](UserModel, "age", nil)
+		assert.Equal(t, minAge, 20)
+
+		maxAge, _ := Max[int](UserModel, "age", bson.M{"name": "John"})
+		assert.Equal(t, maxAge, 20)
+	})
+
+	// Test `SumDecimal`
+	t.Run("Sum Decimal", func(t *testing.T) {
+		newUser = CreateTestUser()
+		price, _ := primitive.ParseDecimal128("0.10")
+		_, _ = UserModel.Native().InsertOne(context.TODO(), bson.M{"_id": NewId(), "name": "Doe", "age": price})
+
+		sum, err := UserModel.SumDecimal("age", nil)
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, sum.String(), "20.10")
+
+		total, _ := UserModel.SumFloat("age", nil)
+		assert.Equal(t, total, 20.1)
+
+		avg, _ := UserModel.AvgDecimal("age", nil)
+		assert.Equal(t, avg.String(), "10.05")
+
+		minAge, _ := UserModel.MinDecimal("age", nil)
+		assert.Equal(t, minAge.String(), "0.10")
+
+		stats, err := UserModel.StatsDecimal([]string{"age"}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, stats["age"].Count, int64(2))
+		assert.Equal(t, stats["age"].Sum.String(), "20.10")
+		assert.Equal(t, stats["age"].Max.String(), "20")
+
+		newUser = CreateTestUser()
+	})
+
+	// Test `TimeSeries`
+	t.Run("Time Series", func(t *testing.T) {
+		DeleteAllUsers()
+		day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+		_, _ = UserModel.Native().InsertMany(context.TODO(), []interface{}{
+			bson.M{"_id": NewId(), "name": "John", "age": 20, "createdAt": day(1)},
+			bson.M{"_id": NewId(), "name": "Doe", "age": 30, "createdAt": day(1)},
+			bson.M{"_id": NewId(), "name": "Jane", "age": 40, "createdAt": day(3)},
+		})
+
+		series, err := UserModel.TimeSeries(TimeSeriesOptions{
+			DateField: "createdAt",
+			Unit:      TimeUnitDay,
+			Keys:      []string{"age"},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Len(t, series, 3)
+		assert.Equal(t, series[0].Values["age"], float64(50))
+		assert.Equal(t, series[1].Count, int64(0))
+		assert.Equal(t, series[2].Values["age"], float64(40))
+
+		newUser = CreateTestUser()
+	})
+
+	// Test `Distinct`
+	t.Run("Distinct", func(t *testing.T) {
+		newUser = CreateTestUser()
+		_, _ = UserModel.InsertOne(&User{ID: NewId(), Name: "Doe", Age: 30})
+		_, _ = UserModel.InsertOne(&User{ID: NewId(), Name: "Doe", Age: 40})
+
+		names, err := Distinct[string](UserModel, "name", nil)
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.ElementsMatch(t, names, []string{"John", "Doe"})
+
+		ages, err := Distinct[int](UserModel, "age", bson.M{"name": "Doe"})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.ElementsMatch(t, ages, []int{30, 40})
+	})
+
+	// Test `GroupBy`
+	t.Run("Group By", func(t *testing.T) {
+		newUser = CreateTestUser()
+		_, _ = UserModel.InsertOne(&User{ID: NewId(), Name: "Doe", Age: 30})
+		_, _ = UserModel.InsertOne(&User{ID: NewId(), Name: "Doe", Age: 40})
+
+		groups, err := GroupBy[string, int](UserModel, "name", "age", nil)
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, groups, map[string]Aggregates[int]{
+			"John": {Count: 1, Sum: 20, Avg: 20, Min: 20, Max: 20},
+			"Doe":  {Count: 2, Sum: 70, Avg: 35, Min: 30, Max: 40},
+		})
+
+		newUser = CreateTestUser()
+	})
+
 	// Test `Exists`
 	t.Run("Exists", func(t *testing.T) {
 		exists, err := UserModel.Exists(bson.M{"_id": newUser.ID})
@@ -439,6 +798,10 @@ func TestModel(t *testing.T) {
 			PerPage:  5,
 			Page:     1,
 			LastPage: 2,
+			HasNext:  true,
+			HasPrev:  false,
+			From:     1,
+			To:       5,
 		})
 	})
 
@@ -457,6 +820,10 @@ func TestModel(t *testing.T) {
 			PerPage:  5,
 			Page:     1,
 			LastPage: 2,
+			HasNext:  true,
+			HasPrev:  false,
+			From:     1,
+			To:       5,
 		})
 	})
 
@@ -475,6 +842,10 @@ func TestModel(t *testing.T) {
 			PerPage:  5,
 			Page:     1,
 			LastPage: 2,
+			HasNext:  true,
+			HasPrev:  false,
+			From:     1,
+			To:       5,
 		})
 	})
 
@@ -494,7 +865,136 @@ func TestModel(t *testing.T) {
 			PerPage:  5,
 			Page:     1,
 			LastPage: 2,
+			HasNext:  true,
+			HasPrev:  false,
+			From:     1,
+			To:       5,
+		})
+	})
+
+	// Test `PaginateWith`
+	t.Run("Paginate With", func(t *testing.T) {
+		CreateMultipleTestUsers(3)
+		_, _ = UserModel.InsertOne(&User{ID: NewId(), Name: "Doe", Age: 10})
+
+		paginated, err := UserModel.PaginateWith(PaginationRequest{
+			PerPage: 2,
+			Sort:    bson.D{{Key: "age", Value: 1}},
+		}, bson.M{})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, paginated.Meta.Page, 1)
+		assert.True(t, paginated.Meta.HasNext)
+		assert.Equal(t, paginated.Data.([]bson.M)[0]["name"], "Doe")
+
+		_, err = UserModel.Paginate(-1, 5, bson.M{})
+		assert.True(t, IsPaginationError(err))
+	})
+
+	// Test `PaginateFacet`
+	t.Run("Paginate Facet", func(t *testing.T) {
+		CreateMultipleTestUsers(10)
+
+		paginated, err := UserModel.PaginateFacet(2, 4, bson.A{bson.M{"$match": bson.M{}}})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, paginated.Meta, PaginatedMeta{
+			Total:    10,
+			PerPage:  4,
+			Page:     2,
+			LastPage: 3,
+			HasNext:  true,
+			HasPrev:  true,
+			From:     5,
+			To:       8,
+		})
+		assert.Len(t, paginated.Data, 4)
+	})
+
+	// Test `PaginateEstimated`
+	t.Run("Paginate Estimated", func(t *testing.T) {
+		CreateMultipleTestUsers(10)
+
+		paginated, err := UserModel.PaginateEstimated(3, 4)
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, paginated.Meta.LastPage, 3)
+		assert.Len(t, paginated.Data, 2)
+	})
+
+	// Test `PaginateAggregateRaw` with an estimated count and a Match
+	t.Run("Paginate Aggregate Raw Estimated With Match", func(t *testing.T) {
+		CreateMultipleTestUsers(10)
+		_, _ = UserModel.InsertOne(&User{ID: NewId(), Name: "Doe", Age: 30})
+
+		paginated, err := UserModel.PaginateAggregateRaw(1, 5, &PaginateAggregateOptions{
+			Match: bson.M{"name": "Doe"},
+			Count: PaginateCountEstimated,
+		})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, 1, paginated.Meta.Total)
+		assert.Len(t, paginated.Data, 1)
+	})
+
+	// Test `PaginateAggregateRaw` with a $facet count
+	t.Run("Paginate Aggregate Raw Facet", func(t *testing.T) {
+		CreateMultipleTestUsers(10)
+
+		paginated, err := UserModel.PaginateAggregateRaw(1, 5, &PaginateAggregateOptions{
+			Count: PaginateCountFacet,
+		})
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, paginated.Meta, PaginatedMeta{
+			Total:    10,
+			PerPage:  5,
+			Page:     1,
+			LastPage: 2,
+			HasNext:  true,
+			HasPrev:  false,
+			From:     1,
+			To:       5,
+		})
+		assert.Len(t, paginated.Data, 5)
+	})
+
+	// Test `Facets`
+	t.Run("Facets", func(t *testing.T) {
+		CreateMultipleTestUsers(6)
+		_, _ = UserModel.InsertOne(&User{ID: NewId(), Name: "Doe", Age: 30})
+
+		faceted, err := UserModel.Facets(bson.M{}, []string{"name", "age"}, 2, 5)
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, faceted.Meta, PaginatedMeta{
+			Total:    7,
+			PerPage:  5,
+			Page:     2,
+			LastPage: 2,
+			HasNext:  false,
+			HasPrev:  true,
+			From:     6,
+			To:       7,
+		})
+		assert.Len(t, faceted.Data, 2)
+		assert.Equal(t, faceted.Facets["name"], []FacetCount{
+			{Value: "John", Count: 6},
+			{Value: "Doe", Count: 1},
 		})
+		assert.Len(t, faceted.Facets["age"], 2)
 	})
 
 	// Test `FindOneAsHelper`
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 /**
@@ -223,9 +225,314 @@ func TestTransaction(t *testing.T) {
 			return nil
 		})
 
-		// AccountModel.txCtx must still be nil — verify by doing a normal op:
+		// AccountModel.boundCtx must still be nil — verify by doing a normal op:
 		count, err := AccountModel.Count(bson.M{})
 		assert.NoError(t, err)
 		assert.Equal(t, int64(2), count)
 	})
 }
+
+func TestTxOptions_backoff(t *testing.T) {
+	opt := TxOptions{Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	for i := 0; i < 20; i++ {
+		first := opt.backoff(1)
+		assert.GreaterOrEqual(t, first, 50*time.Millisecond)
+		assert.LessOrEqual(t, first, 100*time.Millisecond)
+
+		capped := opt.backoff(10)
+		assert.GreaterOrEqual(t, capped, 150*time.Millisecond)
+		assert.LessOrEqual(t, capped, 300*time.Millisecond)
+	}
+
+	assert.Equal(t, time.Duration(0), TxOptions{}.backoff(1))
+}
+
+func TestTransactionWith(t *testing.T) {
+	client := testConnectToDb()
+	requireReplicaSet(t, client)
+
+	AccountModel := MakeModel[*Account](client.Database, "tx_accounts")
+	_, _ = AccountModel.DeleteMany(bson.M{})
+
+	t.Run("Retries transient errors and calls hooks", func(t *testing.T) {
+		attempts, retries, commits := 0, 0, 0
+
+		err := client.TransactionWith(TxOptions{
+			MaxRetries: 3,
+			OnRetry:    func(attempt int, err error) { retries++ },
+			OnCommit:   func() { commits++ },
+		}, func(tx *Tx) error {
+			attempts++
+			if attempts < 3 {
+				return mongo.CommandError{Labels: []string{"TransientTransactionError"}}
+			}
+			_, err := AccountModel.WithTx(tx).InsertOne(&Account{ID: NewId(), Owner: "alice"})
+			return err
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, 2, retries)
+		assert.Equal(t, 1, commits)
+	})
+
+	t.Run("Gives up after MaxRetries", func(t *testing.T) {
+		attempts := 0
+		var aborted error
+
+		err := client.TransactionWith(TxOptions{
+			MaxRetries: 2,
+			OnAbort:    func(err error) { aborted = err },
+		}, func(tx *Tx) error {
+			attempts++
+			return mongo.CommandError{Labels: []string{"TransientTransactionError"}}
+		})
+
+		assert.True(t, IsTransientTxError(err))
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, err, aborted)
+	})
+
+	t.Run("AfterCommit runs once after retries", func(t *testing.T) {
+		attempts, commits := 0, 0
+		rollbacks := 0
+
+		err := client.Transaction(func(tx *Tx) error {
+			attempts++
+			tx.AfterCommit(func() { commits++ })
+			tx.AfterRollback(func(err error) { rollbacks++ })
+			if attempts < 2 {
+				return mongo.CommandError{Labels: []string{"TransientTransactionError"}}
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+		assert.Equal(t, 1, commits)
+		assert.Equal(t, 0, rollbacks)
+	})
+
+	t.Run("AfterRollback runs with the final error", func(t *testing.T) {
+		commits := 0
+		var rolledBack error
+
+		err := client.Transaction(func(tx *Tx) error {
+			tx.AfterCommit(func() { commits++ })
+			tx.AfterRollback(func(err error) { rolledBack = err })
+			return errors.New("boom")
+		})
+
+		assert.EqualError(t, err, "boom")
+		assert.EqualError(t, rolledBack, "boom")
+		assert.Equal(t, 0, commits)
+	})
+
+	t.Run("Does not retry other errors", func(t *testing.T) {
+		attempts := 0
+
+		err := client.TransactionWith(TxOptions{MaxRetries: 3}, func(tx *Tx) error {
+			attempts++
+			return errors.New("boom")
+		})
+
+		assert.EqualError(t, err, "boom")
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestClient_TxFromContext(t *testing.T) {
+	client := &Client{}
+	other := &Client{}
+	tx := &Tx{client: client}
+	ctx := context.WithValue(context.TODO(), txContextKey{}, tx)
+
+	found, ok := client.TxFromContext(ctx)
+	assert.True(t, ok)
+	assert.Same(t, tx, found)
+
+	_, ok = other.TxFromContext(ctx)
+	assert.False(t, ok, "a transaction of another client must not be joined")
+
+	_, ok = client.TxFromContext(context.TODO())
+	assert.False(t, ok)
+}
+
+func TestNestedTransaction(t *testing.T) {
+	client := testConnectToDb()
+	requireReplicaSet(t, client)
+
+	AccountModel := MakeModel[*Account](client.Database, "tx_accounts")
+
+	createAccount := func(ctx context.Context, owner string) error {
+		return client.TransactionContext(ctx, func(tx *Tx) error {
+			_, err := AccountModel.WithTx(tx).InsertOne(&Account{ID: NewId(), Owner: owner})
+			return err
+		})
+	}
+
+	t.Run("Joins the outer transaction", func(t *testing.T) {
+		_, _ = AccountModel.DeleteMany(bson.M{})
+
+		err := client.Transaction(func(tx *Tx) error {
+			if err := createAccount(tx.Context(), "alice"); err != nil {
+				return err
+			}
+			return errors.New("rollback")
+		})
+		assert.Error(t, err)
+
+		count, _ := AccountModel.Count(bson.M{})
+		assert.Equal(t, int64(0), count, "inner write must roll back with the outer transaction")
+	})
+
+	t.Run("Inner failure aborts the outer transaction", func(t *testing.T) {
+		_, _ = AccountModel.DeleteMany(bson.M{})
+
+		err := client.Transaction(func(tx *Tx) error {
+			_, _ = AccountModel.WithTx(tx).InsertOne(&Account{ID: NewId(), Owner: "alice"})
+			// the inner error is ignored by the outer callback
+			_ = client.TransactionContext(tx.Context(), func(tx *Tx) error {
+				return errors.New("inner")
+			})
+			return nil
+		})
+		assert.EqualError(t, err, "inner")
+
+		count, _ := AccountModel.Count(bson.M{})
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("RequiresNew commits independently", func(t *testing.T) {
+		_, _ = AccountModel.DeleteMany(bson.M{})
+
+		err := client.Transaction(func(tx *Tx) error {
+			err := client.TransactionWithContext(tx.Context(), TxOptions{Propagation: TxRequiresNew}, func(tx *Tx) error {
+				_, err := AccountModel.WithTx(tx).InsertOne(&Account{ID: NewId(), Owner: "bob"})
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			return errors.New("rollback")
+		})
+		assert.Error(t, err)
+
+		count, _ := AccountModel.Count(bson.M{})
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("WithContext enrols in the transaction", func(t *testing.T) {
+		_, _ = AccountModel.DeleteMany(bson.M{})
+
+		err := client.Transaction(func(tx *Tx) error {
+			ctx, cancel := context.WithCancel(tx.Context())
+			defer cancel()
+
+			_, err := AccountModel.WithContext(ctx).InsertOne(&Account{ID: NewId(), Owner: "alice"})
+			if err != nil {
+				return err
+			}
+			return errors.New("rollback")
+		})
+		assert.Error(t, err)
+
+		count, _ := AccountModel.Count(bson.M{})
+		assert.Equal(t, int64(0), count)
+	})
+}
+
+func TestTx_CommitRequiresBegin(t *testing.T) {
+	tx := &Tx{}
+	assert.ErrorIs(t, tx.Commit(context.TODO()), ErrTxNotManual)
+	assert.ErrorIs(t, tx.Abort(context.TODO()), ErrTxNotManual)
+}
+
+func TestManualTransaction(t *testing.T) {
+	client := testConnectToDb()
+	requireReplicaSet(t, client)
+
+	AccountModel := MakeModel[*Account](client.Database, "tx_accounts")
+
+	t.Run("Commit", func(t *testing.T) {
+		_, _ = AccountModel.DeleteMany(bson.M{})
+
+		tx, err := client.Begin(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		committed := false
+		tx.AfterCommit(func() { committed = true })
+
+		_, err = AccountModel.WithTx(tx).InsertOne(&Account{ID: NewId(), Owner: "alice"})
+		assert.NoError(t, err)
+
+		// not visible outside the transaction before commit
+		count, _ := AccountModel.Count(bson.M{})
+		assert.Equal(t, int64(0), count)
+
+		assert.NoError(t, tx.Commit(context.TODO()))
+		assert.True(t, committed)
+		assert.ErrorIs(t, tx.Commit(context.TODO()), ErrTxDone)
+		assert.NoError(t, tx.Abort(context.TODO()), "Abort after Commit is a no-op")
+
+		count, _ = AccountModel.Count(bson.M{})
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		_, _ = AccountModel.DeleteMany(bson.M{})
+
+		tx, err := client.Begin(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var rolledBack error
+		tx.AfterRollback(func(err error) { rolledBack = err })
+
+		_, _ = AccountModel.WithTx(tx).InsertOne(&Account{ID: NewId(), Owner: "alice"})
+		assert.NoError(t, tx.Abort(context.TODO()))
+		assert.ErrorIs(t, rolledBack, ErrTxAborted)
+
+		count, _ := AccountModel.Count(bson.M{})
+		assert.Equal(t, int64(0), count)
+	})
+}
+
+func TestSession(t *testing.T) {
+	client := testConnectToDb()
+
+	AccountModel := MakeModel[*Account](client.Database, "tx_accounts")
+	_, _ = AccountModel.DeleteMany(bson.M{})
+
+	id := NewId()
+	err := client.Session(func(s *Session) error {
+		accounts := AccountModel.WithSession(s)
+		if _, err := accounts.InsertOne(&Account{ID: id, Owner: "alice"}); err != nil {
+			return err
+		}
+
+		account, err := accounts.FindOneById(id)
+		if err != nil {
+			return err
+		}
+
+		assert.Equal(t, "alice", account.Owner)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	t.Run("SessionContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := client.SessionContext(ctx, func(s *Session) error {
+			_, err := AccountModel.WithSession(s).FindOneById(id)
+			return err
+		})
+		assert.Error(t, err)
+	})
+}
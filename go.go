@@ -29,56 +29,130 @@ func hasTagOpt(opts []string, opt string) bool {
 	return false
 }
 
-func structToMapWithTags(obj interface{}, tag string) map[string]interface{} {
-	res := map[string]interface{}{}
-	if obj == nil {
-		return res
+// tagOption - Get an option of a `gmongo` style tag, e.g. "sequence" of
+// `gmongo:"sequence=invoices"`. Options without a value return an empty string.
+func tagOption(rawTag string, key string) (value string, ok bool) {
+	for _, opt := range strings.Split(rawTag, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		if name == key {
+			return value, true
+		}
 	}
-	v := reflect.TypeOf(obj)
-	reflectValue := reflect.ValueOf(obj)
-	reflectValue = reflect.Indirect(reflectValue)
+	return "", false
+}
 
-	if v.Kind() == reflect.Ptr {
+// structToMapWithTags - Convert a struct to a map keyed by the names of tag, using
+// the cached plan of its type. Nested structs, including those behind pointers,
+// slices and string-keyed maps, become maps too, while time.Time and
+// primitive.* values are kept as they are.
+func structToMapWithTags(obj interface{}, tag string) map[string]interface{} {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return map[string]interface{}{}
+		}
 		v = v.Elem()
 	}
+	if v.Kind() != reflect.Struct {
+		return map[string]interface{}{}
+	}
 
-	for i := 0; i < v.NumField(); i++ {
-		rawTag := v.Field(i).Tag.Get(tag)
-		name, opts := parseTag(rawTag)
+	res := make(map[string]interface{}, len(planOf(v.Type(), tag).fields))
+	addStructFields(res, v, tag)
+	return res
+}
 
-		fieldType := v.Field(i).Type
-		fieldVal := reflectValue.Field(i)
+// addStructFields - Add the fields of a struct and its inline structs to res
+func addStructFields(res map[string]interface{}, v reflect.Value, tag string) {
+	for _, field := range planOf(v.Type(), tag).fields {
+		fieldVal := v.Field(field.index)
 
-		if hasTagOpt(opts, "inline") {
-			innerType := fieldType
-			innerVal := fieldVal
-			if innerType.Kind() == reflect.Ptr {
-				if innerVal.IsNil() {
+		if field.inline {
+			if fieldVal.Kind() == reflect.Ptr {
+				if fieldVal.IsNil() {
 					continue
 				}
-				innerType = innerType.Elem()
-				innerVal = innerVal.Elem()
-			}
-			if innerType.Kind() != reflect.Struct {
-				continue
-			}
-			sub := structToMapWithTags(innerVal.Interface(), tag)
-			for k, val := range sub {
-				res[k] = val
+				fieldVal = fieldVal.Elem()
 			}
+			addStructFields(res, fieldVal, tag)
 			continue
 		}
 
-		if name == "" || name == "-" {
+		if field.omitEmpty && isEmptyValue(fieldVal) {
+			continue
+		}
+		if !field.hasStructs {
+			res[field.name] = fieldVal.Interface()
 			continue
 		}
+		res[field.name] = mapStructs(fieldVal, tag)
+	}
+}
+
+// mapValue - Convert a field value for structToMapWithTags
+func mapValue(v reflect.Value, tag string) interface{} {
+	if !hasStructs(v.Type()) {
+		return v.Interface()
+	}
+	return mapStructs(v, tag)
+}
 
-		field := fieldVal.Interface()
-		if fieldType.Kind() == reflect.Struct {
-			res[name] = structToMapWithTags(field, tag)
-		} else {
-			res[name] = field
+// mapStructs - Convert a value that may contain structs, see mapValue
+func mapStructs(v reflect.Value, tag string) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return v.Interface()
+		}
+		return mapValue(v.Elem(), tag)
+	case reflect.Struct:
+		res := make(map[string]interface{}, len(planOf(v.Type(), tag).fields))
+		addStructFields(res, v, tag)
+		return res
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v.Interface()
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = mapValue(v.Index(i), tag)
+		}
+		return items
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || v.IsNil() {
+			return v.Interface()
 		}
+		res := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			res[iter.Key().String()] = mapValue(iter.Value(), tag)
+		}
+		return res
 	}
-	return res
+
+	return v.Interface()
+}
+
+// zeroer - Values that know if they are empty, such as time.Time
+type zeroer interface {
+	IsZero() bool
+}
+
+// isEmptyValue - Check if a value is omitted by omitempty, the same way the
+// bson encoder does: structs are only empty if they say so with IsZero
+func isEmptyValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return true
+	}
+	if z, ok := v.Interface().(zeroer); ok {
+		return z.IsZero()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return false
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
 }
@@ -2,8 +2,10 @@ package gmongo
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func Test_RemoveStringFromStringIfExists(t *testing.T) {
@@ -150,3 +152,128 @@ func Test_StructToMapWithTags_Inline(t *testing.T) {
 		}, got)
 	})
 }
+
+func Test_StructToMapWithTags_Values(t *testing.T) {
+	type Item struct {
+		Sku string `bson:"sku"`
+	}
+	type Doc struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		CreatedAt time.Time          `bson:"createdAt"`
+		Note      string             `bson:"note,omitempty"`
+		Tags      []string           `bson:"tags,omitempty"`
+		DeletedAt time.Time          `bson:"deletedAt,omitempty"`
+		Main      *Item              `bson:"main"`
+		Spare     *Item              `bson:"spare"`
+		Items     []Item             `bson:"items"`
+		ByName    map[string]Item    `bson:"byName"`
+		Counts    map[string]int     `bson:"counts"`
+	}
+
+	id := primitive.NewObjectID()
+	now := time.Now()
+
+	t.Run("leaves and omitempty", func(t *testing.T) {
+		got := structToMapWithTags(Doc{ID: id, CreatedAt: now}, "bson")
+		assert.Equal(t, map[string]interface{}{
+			"_id":       id,
+			"createdAt": now,
+			"main":      (*Item)(nil),
+			"spare":     (*Item)(nil),
+			"items":     []Item(nil),
+			"byName":    map[string]Item(nil),
+			"counts":    map[string]int(nil),
+		}, got)
+
+		got = structToMapWithTags(&Doc{Note: "n", Tags: []string{"a"}, DeletedAt: now}, "bson")
+		assert.Equal(t, "n", got["note"])
+		assert.Equal(t, []string{"a"}, got["tags"])
+		assert.Equal(t, now, got["deletedAt"])
+	})
+
+	t.Run("structs behind pointers, slices and maps", func(t *testing.T) {
+		got := structToMapWithTags(Doc{
+			Main:   &Item{Sku: "a"},
+			Items:  []Item{{Sku: "b"}, {Sku: "c"}},
+			ByName: map[string]Item{"d": {Sku: "d"}},
+			Counts: map[string]int{"e": 1},
+		}, "bson")
+		assert.Equal(t, map[string]interface{}{"sku": "a"}, got["main"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"sku": "b"},
+			map[string]interface{}{"sku": "c"},
+		}, got["items"])
+		assert.Equal(t, map[string]interface{}{"d": map[string]interface{}{"sku": "d"}}, got["byName"])
+		assert.Equal(t, map[string]int{"e": 1}, got["counts"])
+	})
+
+	t.Run("nil and non-struct values", func(t *testing.T) {
+		assert.Equal(t, map[string]interface{}{}, structToMapWithTags(nil, "bson"))
+		assert.Equal(t, map[string]interface{}{}, structToMapWithTags((*Doc)(nil), "bson"))
+		assert.Equal(t, map[string]interface{}{}, structToMapWithTags("doc", "bson"))
+	})
+}
+
+func Test_TagOption(t *testing.T) {
+	value, ok := tagOption("sequence=invoices,encrypt", "sequence")
+	assert.True(t, ok)
+	assert.Equal(t, "invoices", value)
+
+	value, ok = tagOption("sequence=invoices, encrypt", "encrypt")
+	assert.True(t, ok)
+	assert.Equal(t, "", value)
+
+	_, ok = tagOption("", "sequence")
+	assert.False(t, ok)
+}
+
+type benchAddress struct {
+	Street string `bson:"street" json:"street"`
+	City   string `bson:"city" json:"city"`
+}
+
+type benchUser struct {
+	Stamps    `bson:",inline"`
+	Name      string         `bson:"name" json:"name"`
+	Email     string         `bson:"email" json:"email"`
+	Age       int            `bson:"age" json:"age"`
+	Verified  bool           `bson:"verified" json:"verified"`
+	Bio       string         `bson:"bio,omitempty" json:"bio,omitempty"`
+	UpdatedAt time.Time      `bson:"updatedAt" json:"updatedAt"`
+	Address   benchAddress   `bson:"address" json:"address"`
+	Previous  []benchAddress `bson:"previous" json:"previous"`
+}
+
+func (u *benchUser) GetID() primitive.ObjectID { return u.ID }
+
+func BenchmarkStructToMapWithTags(b *testing.B) {
+	user := &benchUser{
+		Stamps:    Stamps{ID: primitive.NewObjectID(), CreatedAt: 1000},
+		Name:      "John",
+		Email:     "john@example.com",
+		Age:       20,
+		Verified:  true,
+		UpdatedAt: time.Now(),
+		Address:   benchAddress{Street: "1 Main St", City: "Lagos"},
+		Previous:  []benchAddress{{Street: "2 Side St", City: "Abuja"}},
+	}
+
+	for _, tag := range []string{"bson", "json"} {
+		b.Run(tag, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				structToMapWithTags(user, tag)
+			}
+		})
+	}
+}
+
+func BenchmarkModel_GetPublicFields(b *testing.B) {
+	model := Model[*benchUser]{PublicFields: []string{"_id", "name", "address.city"}}
+	user := &benchUser{Name: "John", Address: benchAddress{City: "Lagos"}}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		model.GetPublicFields(user)
+	}
+}
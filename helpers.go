@@ -21,7 +21,7 @@ func NewId() primitive.ObjectID {
 	return primitive.NewObjectID()
 }
 
-// NewUUid - Generate a new UUID
+// NewUUid - Generate a new UUID string, see NewUUID for a UUID stored as binary
 func NewUUid() string {
 	// use uuid version 4
 	return uuid.NewString()
@@ -37,6 +37,34 @@ func IsFindOneError(err error) bool {
 	return err != nil && !IsNoDocumentsError(err)
 }
 
+// hasErrorLabel - Check if the error is a server error with the given label
+func hasErrorLabel(err error, label string) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorLabel(label)
+}
+
+// IsTransientTxError - Check if the error is labelled TransientTransactionError,
+// meaning the whole transaction can safely be retried
+func IsTransientTxError(err error) bool {
+	return hasErrorLabel(err, "TransientTransactionError")
+}
+
+// isUnknownCommitResult - Check if a commit failed without knowing if it was applied
+func isUnknownCommitResult(err error) bool {
+	return hasErrorLabel(err, "UnknownTransactionCommitResult")
+}
+
+// IsDuplicateKeyError - Check if the error is a duplicate key (E11000) error
+func IsDuplicateKeyError(err error) bool {
+	return mongo.IsDuplicateKeyError(err)
+}
+
+// IsWriteConflict - Check if the error is a WriteConflict (code 112) error
+func IsWriteConflict(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(112)
+}
+
 // SumMany - Sum many documents
 //
 // Example:
@@ -49,7 +77,7 @@ func IsFindOneError(err error) bool {
 //
 //	sum, _ := gmongo.SumMany(Model, []string{"credit", "debit"})
 //	// sum will be {credit: 300, debit: 700}
-func SumMany[V Number, T ModelData](coll *Model[T], keys []string, filter interface{}) (bson.M, error) {
+func SumMany[V Number, T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], keys []string, filter interface{}) (bson.M, error) {
 	group := bson.M{"_id": nil}
 	result := bson.M{}
 
@@ -84,15 +112,8 @@ func SumMany[V Number, T ModelData](coll *Model[T], keys []string, filter interf
 		}
 
 		// Convert to requested type V
-		switch v := value.(type) {
-		case float64:
-			result[key] = V(v)
-		case int32:
-			result[key] = V(v)
-		case int64:
-			result[key] = V(v)
-		case int:
-			result[key] = V(v)
+		if v, ok := toNumber[V](value); ok {
+			result[key] = v
 		}
 	}
 
@@ -110,7 +131,7 @@ func SumMany[V Number, T ModelData](coll *Model[T], keys []string, filter interf
 // ]
 // sum, _ := UserModel.Sum(Model, "credit", nil)
 // // sum will be 300
-func Sum[V Number, T ModelData](coll *Model[T], key string, filter interface{}) (V, error) {
+func Sum[V Number, T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], key string, filter interface{}) (V, error) {
 	res, err := SumMany[V, T](coll, []string{key}, filter)
 	if err != nil {
 		return V(0), err
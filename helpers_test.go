@@ -2,6 +2,7 @@ package gmongo
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -44,3 +45,24 @@ func Test_IsFindOneError(t *testing.T) {
 	assert.False(t, IsFindOneError(mongo.ErrNoDocuments))
 	assert.True(t, IsFindOneError(errors.New("some error")))
 }
+
+// Test `IsTransientTxError` function
+func Test_IsTransientTxError(t *testing.T) {
+	assert.False(t, IsTransientTxError(nil))
+	assert.False(t, IsTransientTxError(errors.New("some error")))
+	assert.True(t, IsTransientTxError(mongo.CommandError{Labels: []string{"TransientTransactionError"}}))
+	assert.True(t, IsTransientTxError(fmt.Errorf("wrapped: %w", mongo.CommandError{Labels: []string{"TransientTransactionError"}})))
+}
+
+// Test `IsDuplicateKeyError` function
+func Test_IsDuplicateKeyError(t *testing.T) {
+	assert.False(t, IsDuplicateKeyError(errors.New("some error")))
+	assert.True(t, IsDuplicateKeyError(mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}))
+}
+
+// Test `IsWriteConflict` function
+func Test_IsWriteConflict(t *testing.T) {
+	assert.False(t, IsWriteConflict(errors.New("some error")))
+	assert.True(t, IsWriteConflict(mongo.CommandError{Code: 112}))
+	assert.False(t, IsWriteConflict(mongo.CommandError{Code: 11000}))
+}
@@ -0,0 +1,240 @@
+package gmongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// HistoryOptions - Options of Model[T].EnableHistory
+type HistoryOptions struct {
+	// Collection - Collection versions are written to, defaults to "<collection>_history"
+	Collection string
+}
+
+// HistoryVersion - A previous version of a document
+type HistoryVersion[T any] struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	DocumentID interface{}        `bson:"documentId" json:"documentId"`
+	// Version - Starts at 1 for the first version of the document
+	Version int64 `bson:"version" json:"version"`
+	// Operation - The write that replaced this version
+	Operation AuditOperation `bson:"operation" json:"operation"`
+	Document  T              `bson:"document" json:"document"`
+	// ArchivedAt - When this version stopped being the current one
+	ArchivedAt time.Time `bson:"archivedAt" json:"archivedAt"`
+}
+
+// GetID - Get the ID of the version
+func (v *HistoryVersion[T]) GetID() primitive.ObjectID {
+	return v.ID
+}
+
+// EnableHistory - Keep every previous version of the documents of the model.
+// Every document changed by UpdateOne, UpdateMany, ReplaceOne, DeleteOne,
+// DeleteMany and the FindOneAnd methods (including ModelHelper writes) is
+// copied to the history collection as it was before the write. UpdateMany
+// and DeleteMany read the matching documents first and only write those.
+//
+// The latest version number and the creation time of each document are kept
+// in a "<history collection>_versions" collection.
+func (coll *ModelOf[T, ID]) EnableHistory(opts ...HistoryOptions) {
+	opt := HistoryOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Collection == "" {
+		opt.Collection = coll.CollectionName + "_history"
+	}
+
+	coll.history = &opt
+}
+
+// historyCollection - Get the collection versions of the model are written to
+func (coll *ModelOf[T, ID]) historyCollection() *mongo.Collection {
+	name := coll.CollectionName + "_history"
+	if coll.history != nil {
+		name = coll.history.Collection
+	}
+	return coll.Native().Database().Collection(name)
+}
+
+// versionsCollection - Get the collection of the latest version and creation
+// time of each document, keyed by document id
+func (coll *ModelOf[T, ID]) versionsCollection() *mongo.Collection {
+	history := coll.historyCollection()
+	return history.Database().Collection(history.Name() + "_versions")
+}
+
+// EnsureHistoryIndexes - Create the indexes used to look up versions.
+// The unique version index guards against recording a version twice.
+func (coll *ModelOf[T, ID]) EnsureHistoryIndexes() error {
+	_, err := coll.historyCollection().Indexes().CreateMany(coll.ctx(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "documentId", Value: 1}, {Key: "version", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "documentId", Value: 1}, {Key: "archivedAt", Value: 1}}},
+	})
+	return err
+}
+
+// documentVersions - The latest version and creation time of a document
+type documentVersions struct {
+	Version   int64      `bson:"version"`
+	CreatedAt *time.Time `bson:"createdAt,omitempty"`
+}
+
+// nextVersion - Atomically allocate the next version number of a document
+func (coll *ModelOf[T, ID]) nextVersion(id interface{}) (int64, error) {
+	var versions documentVersions
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		err = coll.versionsCollection().FindOneAndUpdate(coll.ctx(),
+			bson.M{"_id": id},
+			bson.M{"$inc": bson.M{"version": 1}},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+		).Decode(&versions)
+
+		// a concurrent write created the counter first, it can be incremented now
+		if !IsDuplicateKeyError(err) {
+			break
+		}
+	}
+	return versions.Version, err
+}
+
+// writeCreated - Record when a document was inserted, so AsOf knows it did not exist before
+func (coll *ModelOf[T, ID]) writeCreated(id interface{}) error {
+	_, err := coll.versionsCollection().UpdateOne(coll.ctx(),
+		bson.M{"_id": id},
+		bson.M{"$setOnInsert": bson.M{"version": 0, "createdAt": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// createdAt - Get when a document was inserted, from its record or its ObjectID
+func (coll *ModelOf[T, ID]) createdAt(id interface{}) (time.Time, bool, error) {
+	var versions documentVersions
+	err := coll.versionsCollection().FindOne(coll.ctx(), bson.M{"_id": id}).Decode(&versions)
+	if IsFindOneError(err) {
+		return time.Time{}, false, err
+	}
+	if versions.CreatedAt != nil {
+		return *versions.CreatedAt, true, nil
+	}
+
+	if oid, ok := id.(primitive.ObjectID); ok {
+		return oid.Timestamp(), true, nil
+	}
+	return time.Time{}, false, nil
+}
+
+// writeSnapshot - Copy the previous version of a document to the history collection
+func (coll *ModelOf[T, ID]) writeSnapshot(op AuditOperation, id interface{}, before bson.M) error {
+	version, err := coll.nextVersion(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = coll.historyCollection().InsertOne(coll.ctx(), bson.M{
+		"_id":        primitive.NewObjectID(),
+		"documentId": id,
+		"version":    version,
+		"operation":  op,
+		"document":   before,
+		"archivedAt": time.Now(),
+	})
+	return err
+}
+
+// History - Get the previous versions of a document, oldest first
+func (coll *ModelOf[T, ID]) History(id ID) ([]HistoryVersion[T], error) {
+	cursor, err := coll.historyCollection().Find(coll.ctx(),
+		bson.M{"documentId": id},
+		options.Find().SetSort(bson.D{{Key: "version", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]HistoryVersion[T], 0)
+	if err = coll.decodeAll(cursor, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// Version - Get a previous version of a document
+func (coll *ModelOf[T, ID]) Version(id ID, version int64) (*HistoryVersion[T], error) {
+	var result HistoryVersion[T]
+	err := coll.decodeResult(coll.historyCollection().FindOne(coll.ctx(), bson.M{"documentId": id, "version": version}), &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AsOf - Get a document as it was at the given time.
+// Returns mongo.ErrNoDocuments if the document did not exist at that time.
+//
+// The creation time of documents inserted before history was enabled is only
+// known for ObjectID ids, other such documents are assumed to have existed.
+func (coll *ModelOf[T, ID]) AsOf(id ID, at time.Time) (T, error) {
+	var result T
+
+	created, ok, err := coll.createdAt(id)
+	if err != nil {
+		return result, err
+	}
+	if ok && at.Before(created) {
+		return result, mongo.ErrNoDocuments
+	}
+
+	// the first version archived after `at` was the current one at that time
+	var version HistoryVersion[T]
+	err = coll.decodeResult(coll.historyCollection().FindOne(coll.ctx(),
+		bson.M{"documentId": id, "archivedAt": bson.M{"$gt": at}},
+		options.FindOne().SetSort(bson.D{{Key: "version", Value: 1}}),
+	), &version)
+
+	switch {
+	case err == nil:
+		result = version.Document
+	case IsNoDocumentsError(err):
+		// not changed since, so it is the current document
+		if result, err = coll.FindOne(bson.M{"_id": id}); err != nil {
+			return result, err
+		}
+	default:
+		return result, err
+	}
+
+	return result, nil
+}
+
+// RevertTo - Restore a previous version of the model instance, see Model[T].History.
+// The current version is archived first when history is enabled, and a
+// deleted document is inserted again.
+func (m ModelHelperOf[T, ID]) RevertTo(version int64) (*mongo.UpdateResult, error) {
+	previous, err := m.Model.Version(m.GetID(), version)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := m.Model.ReplaceOne(bson.M{"_id": m.GetID()}, previous.Document, options.Replace().SetUpsert(true))
+	if err != nil {
+		return res, err
+	}
+
+	*m.Data = previous.Document
+	return res, nil
+}
@@ -0,0 +1,200 @@
+package gmongo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestHistory(t *testing.T) {
+	client := testConnectToDb()
+
+	UserModel := MakeModel[*User](client.Database, "history_users")
+	UserModel.EnableHistory()
+
+	reset := func() {
+		_, _ = UserModel.Native().DeleteMany(context.TODO(), bson.M{})
+		_, _ = UserModel.historyCollection().DeleteMany(context.TODO(), bson.M{})
+		_, _ = UserModel.versionsCollection().DeleteMany(context.TODO(), bson.M{})
+	}
+
+	assert.Nil(t, UserModel.EnsureHistoryIndexes())
+	assert.Equal(t, "history_users_history", UserModel.historyCollection().Name())
+
+	// writes a user with three versions: age 20, 21 and 22, returning the
+	// time each version was current
+	setup := func() (*User, []time.Time) {
+		reset()
+
+		user := &User{ID: primitive.NewObjectID(), Name: "John", Age: 20}
+		_, err := UserModel.InsertOne(user)
+		assert.Nil(t, err)
+
+		var times []time.Time
+		for _, age := range []int{21, 22} {
+			time.Sleep(10 * time.Millisecond)
+			times = append(times, time.Now())
+			time.Sleep(10 * time.Millisecond)
+
+			_, err = UserModel.Helpers(user).Update(bson.M{"age": age})
+			assert.Nil(t, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		times = append(times, time.Now())
+
+		return user, times
+	}
+
+	t.Run("History", func(t *testing.T) {
+		user, _ := setup()
+
+		versions, err := UserModel.History(user.ID)
+		assert.Nil(t, err)
+		assert.Len(t, versions, 2)
+		assert.Equal(t, int64(1), versions[0].Version)
+		assert.Equal(t, 20, versions[0].Document.Age)
+		assert.Equal(t, AuditUpdate, versions[0].Operation)
+		assert.Equal(t, int64(2), versions[1].Version)
+		assert.Equal(t, 21, versions[1].Document.Age)
+	})
+
+	t.Run("AsOf", func(t *testing.T) {
+		user, times := setup()
+
+		for i, age := range []int{20, 21, 22} {
+			doc, err := UserModel.AsOf(user.ID, times[i])
+			assert.Nil(t, err)
+			assert.Equal(t, age, doc.Age)
+		}
+
+		_, err := UserModel.AsOf(user.ID, user.ID.Timestamp().Add(-time.Hour))
+		assert.True(t, IsNoDocumentsError(err))
+
+		_, err = UserModel.DeleteOne(bson.M{"_id": user.ID})
+		assert.Nil(t, err)
+
+		_, err = UserModel.AsOf(user.ID, time.Now())
+		assert.True(t, IsNoDocumentsError(err))
+
+		doc, err := UserModel.AsOf(user.ID, times[2])
+		assert.Nil(t, err)
+		assert.Equal(t, 22, doc.Age)
+	})
+
+	t.Run("RevertTo", func(t *testing.T) {
+		user, _ := setup()
+
+		helper := UserModel.Helpers(user)
+		_, err := helper.RevertTo(1)
+		assert.Nil(t, err)
+		assert.Equal(t, 20, (*helper.Data).Age)
+
+		current, err := UserModel.FindOneById(user.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, 20, current.Age)
+
+		// the reverted version is archived too
+		versions, _ := UserModel.History(user.ID)
+		assert.Len(t, versions, 3)
+		assert.Equal(t, 22, versions[2].Document.Age)
+
+		// deleted documents are restored
+		_, err = UserModel.Helpers(user).Delete()
+		assert.Nil(t, err)
+		_, err = UserModel.Helpers(user).RevertTo(2)
+		assert.Nil(t, err)
+
+		current, err = UserModel.FindOneById(user.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, 21, current.Age)
+	})
+
+	t.Run("Concurrent writes get distinct versions", func(t *testing.T) {
+		user, _ := setup()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(age int) {
+				defer wg.Done()
+				_, err := UserModel.UpdateOne(bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"age": 100 + age}})
+				assert.Nil(t, err)
+			}(i)
+		}
+		wg.Wait()
+
+		versions, err := UserModel.History(user.ID)
+		assert.Nil(t, err)
+		assert.Len(t, versions, 12)
+		for i, version := range versions {
+			assert.Equal(t, int64(i+1), version.Version)
+		}
+	})
+
+	t.Run("Many and FindOneAnd writes are versioned", func(t *testing.T) {
+		user, _ := setup()
+		jane := &User{ID: primitive.NewObjectID(), Name: "Jane", Age: 30}
+		_, err := UserModel.InsertOne(jane)
+		assert.Nil(t, err)
+
+		_, err = UserModel.UpdateMany(bson.M{}, bson.M{"$inc": bson.M{"age": 1}})
+		assert.Nil(t, err)
+
+		updated, err := UserModel.FindOneAndUpdate(bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"age": 40}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After))
+		assert.Nil(t, err)
+		assert.Equal(t, 40, updated.Age)
+
+		previous, err := UserModel.FindOneAndReplace(bson.M{"_id": user.ID}, &User{ID: user.ID, Name: "Johnny", Age: 41})
+		assert.Nil(t, err)
+		assert.Equal(t, 40, previous.Age)
+
+		_, err = UserModel.FindOneAndDelete(bson.M{"_id": user.ID})
+		assert.Nil(t, err)
+		_, err = UserModel.DeleteMany(bson.M{"_id": jane.ID})
+		assert.Nil(t, err)
+
+		versions, err := UserModel.History(user.ID)
+		assert.Nil(t, err)
+		ages := []int{}
+		for _, version := range versions {
+			ages = append(ages, version.Document.Age)
+		}
+		assert.Equal(t, []int{20, 21, 22, 23, 40, 41}, ages)
+		assert.Equal(t, AuditDelete, versions[5].Operation)
+
+		versions, err = UserModel.History(jane.ID)
+		assert.Nil(t, err)
+		assert.Len(t, versions, 2)
+		assert.Equal(t, 31, versions[1].Document.Age)
+
+		_, err = UserModel.FindOneAndUpdate(bson.M{"_id": jane.ID}, bson.M{"$set": bson.M{"age": 1}},
+			options.FindOneAndUpdate().SetProjection(bson.M{"name": 1}))
+		assert.Equal(t, errTrackedProjection, err)
+	})
+
+	t.Run("AsOf before a non-ObjectID document was created", func(t *testing.T) {
+		InvoiceModel := MakeModelOf[*Invoice, string](client.Database, "history_invoices")
+		InvoiceModel.EnableHistory()
+		_, _ = InvoiceModel.Native().DeleteMany(context.TODO(), bson.M{})
+		_, _ = InvoiceModel.versionsCollection().DeleteMany(context.TODO(), bson.M{})
+
+		before := time.Now()
+		time.Sleep(10 * time.Millisecond)
+		_, err := InvoiceModel.InsertOne(&Invoice{ID: "INV-1", Amount: 10})
+		assert.Nil(t, err)
+
+		_, err = InvoiceModel.AsOf("INV-1", before)
+		assert.True(t, IsNoDocumentsError(err))
+
+		invoice, err := InvoiceModel.AsOf("INV-1", time.Now())
+		assert.Nil(t, err)
+		assert.Equal(t, "INV-1", invoice.ID)
+	})
+}
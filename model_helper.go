@@ -1,48 +1,69 @@
 package gmongo
 
 import (
-	"github.com/samber/lo"
+	"reflect"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // ModelHelper - A helper for models, this struct includes all the functions for a model instance
-type ModelHelper[T ModelData] struct {
+type ModelHelper[T ModelData] = ModelHelperOf[T, primitive.ObjectID]
+
+// ModelHelperOf - ModelHelper of a model keyed by IDs of type ID
+type ModelHelperOf[T ModelDataOf[ID], ID any] struct {
 	Data  *T
-	Model *Model[T]
+	Model *ModelOf[T, ID]
 }
 
 // GetModelHelper - Get a model helper for a model instance
-func GetModelHelper[T ModelData](model *Model[T], data *T) *ModelHelper[T] {
-	return &ModelHelper[T]{
+func GetModelHelper[T ModelDataOf[ID], ID any](model *ModelOf[T, ID], data *T) *ModelHelperOf[T, ID] {
+	return &ModelHelperOf[T, ID]{
 		Data:  data,
 		Model: model,
 	}
 }
 
+// isNil - Check if a value is nil, including nil pointers in an interface
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
 // GetPublicFields - Get the public fields of a model instance
-func (m ModelHelper[T]) GetPublicFields() bson.M {
-	modelMap := structToMapWithTags(*m.Data, "bson")
-	return lo.PickByKeys(modelMap, m.Model.PublicFields)
+func (m ModelHelperOf[T, ID]) GetPublicFields() bson.M {
+	return m.Model.GetPublicFields(*m.Data)
 }
 
-// GetID - Get the ID of a model instance
-func (m ModelHelper[T]) GetID() primitive.ObjectID {
+// GetID - Get the ID of a model instance, the zero ID if it has no data
+func (m ModelHelperOf[T, ID]) GetID() ID {
+	if m.Data == nil || isNil(*m.Data) {
+		var zero ID
+		return zero
+	}
 	return (*m.Data).GetID()
 }
 
 // UpdateRaw - Update a model instance with raw data
-func (m ModelHelper[T]) UpdateRaw(update bson.M) (*mongo.UpdateResult, error) {
+func (m ModelHelperOf[T, ID]) UpdateRaw(update bson.M) (*mongo.UpdateResult, error) {
 	return m.Model.UpdateOne(bson.M{"_id": m.GetID()}, update)
 }
 
 // Update - Update a model instance
-func (m ModelHelper[T]) Update(set bson.M) (*mongo.UpdateResult, error) {
+func (m ModelHelperOf[T, ID]) Update(set bson.M) (*mongo.UpdateResult, error) {
 	return m.UpdateRaw(bson.M{"$set": set})
 }
 
 // Delete - Delete a model instance
-func (m ModelHelper[T]) Delete() (*mongo.DeleteResult, error) {
+func (m ModelHelperOf[T, ID]) Delete() (*mongo.DeleteResult, error) {
 	return m.Model.DeleteOne(bson.M{"_id": m.GetID()})
 }
@@ -0,0 +1,383 @@
+package gmongo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultOutboxCollection - Collection of outbox messages when Client.OutboxCollection is empty
+const DefaultOutboxCollection = "gmongo_outbox"
+
+// OutboxStatus - Delivery status of an outbox message
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxSent    OutboxStatus = "sent"
+	// OutboxDead - The message failed MaxAttempts times and is no longer retried
+	OutboxDead OutboxStatus = "dead"
+)
+
+// OutboxMessage - A message written by Tx.Publish
+type OutboxMessage struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Topic       string             `bson:"topic" json:"topic"`
+	Payload     bson.RawValue      `bson:"payload" json:"-"`
+	Status      OutboxStatus       `bson:"status" json:"status"`
+	Attempts    int                `bson:"attempts" json:"attempts"`
+	LastError   string             `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	AvailableAt time.Time          `bson:"availableAt" json:"availableAt"`
+	SentAt      *time.Time         `bson:"sentAt,omitempty" json:"sentAt,omitempty"`
+	LockedBy    string             `bson:"lockedBy,omitempty" json:"-"`
+	LockedUntil *time.Time         `bson:"lockedUntil,omitempty" json:"-"`
+	// registry - Registry of the client the message was claimed with
+	registry *bsoncodec.Registry
+}
+
+func (m *OutboxMessage) GetID() primitive.ObjectID { return m.ID }
+
+// Decode - Decode the payload of the message into v, with the registry of
+// the dispatcher's client
+func (m *OutboxMessage) Decode(v interface{}) error {
+	if m.registry != nil {
+		return m.Payload.UnmarshalWithRegistry(m.registry, v)
+	}
+	return m.Payload.Unmarshal(v)
+}
+
+// Publisher - Delivers outbox messages to a queue, a broker, a webhook etc.
+//
+// Messages are delivered at least once, so consumers should be idempotent
+// (e.g. deduplicate on msg.ID).
+type Publisher interface {
+	Publish(ctx context.Context, msg *OutboxMessage) error
+}
+
+// PublisherFunc - Use a function as a Publisher
+type PublisherFunc func(ctx context.Context, msg *OutboxMessage) error
+
+func (f PublisherFunc) Publish(ctx context.Context, msg *OutboxMessage) error {
+	return f(ctx, msg)
+}
+
+// ErrOutboxLockLost - Reported when a message was claimed by another dispatcher
+// before its delivery was recorded, e.g. because publishing took longer than
+// LockTimeout. The delivery is not counted as sent.
+var ErrOutboxLockLost = errors.New("gmongo: outbox message lock was lost before its delivery was recorded")
+
+// outbox - Get the outbox collection of the client
+func (c *Client) outbox() *mongo.Collection {
+	name := c.OutboxCollection
+	if name == "" {
+		name = DefaultOutboxCollection
+	}
+	return c.Database.Collection(name)
+}
+
+// Publish writes a message to the outbox as part of the transaction. The
+// message is only delivered by an OutboxDispatcher if the transaction commits.
+//
+//	err := client.TransactionContext(ctx, func(tx *gmongo.Tx) error {
+//		if _, err := OrderModel.WithTx(tx).InsertOne(order); err != nil {
+//			return err
+//		}
+//		return tx.Publish("order.created", bson.M{"orderId": order.ID})
+//	})
+func (t *Tx) Publish(topic string, payload interface{}) error {
+	now := time.Now()
+	_, err := t.client.outbox().InsertOne(t.sc, bson.M{
+		"_id":         NewId(),
+		"topic":       topic,
+		"payload":     payload,
+		"status":      OutboxPending,
+		"attempts":    0,
+		"createdAt":   now,
+		"availableAt": now,
+	})
+	return err
+}
+
+// OutboxDispatcherOptions - Options of an OutboxDispatcher
+type OutboxDispatcherOptions struct {
+	// WorkerID - Identifies the dispatcher in claimed messages, defaults to a random UUID
+	WorkerID string
+	// Topics - Only dispatch messages of these topics, defaults to all
+	Topics []string
+	// PollInterval - Delay between polls when the outbox is empty, defaults to 1s
+	PollInterval time.Duration
+	// ChangeStream - Also wake up on inserts using a change stream (requires a replica set)
+	ChangeStream bool
+	// BatchSize - Maximum number of messages dispatched per poll, defaults to 100
+	BatchSize int
+	// LockTimeout - How long a claimed message is reserved for this dispatcher, defaults to 30s
+	LockTimeout time.Duration
+	// MaxAttempts - Failed deliveries before a message is marked dead, defaults to 10
+	MaxAttempts int
+	// Backoff - Delay before the first redelivery, doubled on every attempt, defaults to 1s
+	Backoff time.Duration
+	// MaxBackoff - Upper bound of the delay between redeliveries, defaults to 5m
+	MaxBackoff time.Duration
+	// OnError - Called with errors that don't stop the dispatcher
+	OnError func(err error)
+}
+
+// OutboxDispatcher - Delivers outbox messages to a Publisher.
+//
+// Any number of dispatchers can run against the same outbox: each message is
+// claimed atomically for LockTimeout before it is published, and is released
+// for another dispatcher if it isn't marked sent within that time.
+type OutboxDispatcher struct {
+	client    *Client
+	publisher Publisher
+	opt       OutboxDispatcherOptions
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewOutboxDispatcher - Create a dispatcher of the client outbox
+func (c *Client) NewOutboxDispatcher(publisher Publisher, opt OutboxDispatcherOptions) *OutboxDispatcher {
+	if opt.WorkerID == "" {
+		opt.WorkerID = NewUUid()
+	}
+	if opt.PollInterval <= 0 {
+		opt.PollInterval = time.Second
+	}
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = 100
+	}
+	if opt.LockTimeout <= 0 {
+		opt.LockTimeout = 30 * time.Second
+	}
+	if opt.MaxAttempts <= 0 {
+		opt.MaxAttempts = 10
+	}
+	if opt.Backoff <= 0 {
+		opt.Backoff = time.Second
+	}
+	if opt.MaxBackoff <= 0 {
+		opt.MaxBackoff = 5 * time.Minute
+	}
+
+	return &OutboxDispatcher{client: c, publisher: publisher, opt: opt}
+}
+
+// EnsureIndexes - Create the indexes used to claim messages
+func (d *OutboxDispatcher) EnsureIndexes(ctx context.Context) error {
+	_, err := d.client.outbox().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "availableAt", Value: 1}},
+	})
+	return err
+}
+
+// backoff - Delay before redelivering a message that failed attempts times
+func (d *OutboxDispatcher) backoff(attempts int) time.Duration {
+	delay := d.opt.Backoff << (attempts - 1)
+	if delay <= 0 || delay > d.opt.MaxBackoff {
+		return d.opt.MaxBackoff
+	}
+	return delay
+}
+
+// reportError - Pass an error to OnError
+func (d *OutboxDispatcher) reportError(err error) {
+	if err != nil && d.opt.OnError != nil {
+		d.opt.OnError(err)
+	}
+}
+
+// claim - Atomically reserve the next available message for this dispatcher
+func (d *OutboxDispatcher) claim(ctx context.Context) (*OutboxMessage, error) {
+	now := time.Now()
+	filter := bson.M{
+		"status":      OutboxPending,
+		"availableAt": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"lockedUntil": bson.M{"$exists": false}},
+			bson.M{"lockedUntil": bson.M{"$lte": now}},
+		},
+	}
+	if len(d.opt.Topics) > 0 {
+		filter["topic"] = bson.M{"$in": d.opt.Topics}
+	}
+
+	var msg OutboxMessage
+	err := d.client.outbox().FindOneAndUpdate(ctx, filter,
+		bson.M{"$set": bson.M{"lockedBy": d.opt.WorkerID, "lockedUntil": now.Add(d.opt.LockTimeout)}},
+		options.FindOneAndUpdate().SetSort(bson.M{"_id": 1}).SetReturnDocument(options.After),
+	).Decode(&msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.registry = d.client.Registry
+
+	return &msg, nil
+}
+
+// deliver - Publish a claimed message and record the outcome
+func (d *OutboxDispatcher) deliver(ctx context.Context, msg *OutboxMessage) error {
+	// only update the message while this dispatcher still holds this claim of it
+	filter := bson.M{"_id": msg.ID, "lockedBy": d.opt.WorkerID, "lockedUntil": msg.LockedUntil}
+
+	publishErr := d.publisher.Publish(ctx, msg)
+	now := time.Now()
+
+	var update bson.M
+	if publishErr == nil {
+		update = bson.M{
+			"$set":   bson.M{"status": OutboxSent, "sentAt": now},
+			"$inc":   bson.M{"attempts": 1},
+			"$unset": bson.M{"lockedBy": "", "lockedUntil": ""},
+		}
+	} else {
+		attempts := msg.Attempts + 1
+		set := bson.M{"lastError": publishErr.Error()}
+		if attempts >= d.opt.MaxAttempts {
+			set["status"] = OutboxDead
+		} else {
+			set["availableAt"] = now.Add(d.backoff(attempts))
+		}
+
+		update = bson.M{
+			"$set":   set,
+			"$inc":   bson.M{"attempts": 1},
+			"$unset": bson.M{"lockedBy": "", "lockedUntil": ""},
+		}
+	}
+
+	// record the outcome even if ctx was cancelled while publishing
+	res, err := d.client.outbox().UpdateOne(context.WithoutCancel(ctx), filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrOutboxLockLost
+	}
+
+	return publishErr
+}
+
+// DispatchOnce - Deliver up to BatchSize available messages and return how many were sent
+func (d *OutboxDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	sent := 0
+
+	for i := 0; i < d.opt.BatchSize; i++ {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		msg, err := d.claim(ctx)
+		if IsNoDocumentsError(err) {
+			return sent, nil
+		}
+		if err != nil {
+			return sent, err
+		}
+
+		if err = d.deliver(ctx, msg); err != nil {
+			d.reportError(err)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// watch - Signal wake on every insert into the outbox until ctx is done
+func (d *OutboxDispatcher) watch(ctx context.Context, wake chan<- struct{}) {
+	stream, err := d.client.outbox().Watch(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": "insert"}}},
+	})
+	if err != nil {
+		d.reportError(err)
+		return
+	}
+	defer stream.Close(context.WithoutCancel(ctx))
+
+	for stream.Next(ctx) {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+
+	if ctx.Err() == nil {
+		// polling keeps the dispatcher working without the change stream
+		d.reportError(stream.Err())
+	}
+}
+
+// Run - Dispatch messages until ctx is cancelled
+func (d *OutboxDispatcher) Run(ctx context.Context) error {
+	wake := make(chan struct{}, 1)
+	if d.opt.ChangeStream {
+		go d.watch(ctx, wake)
+	}
+
+	for {
+		sent, err := d.DispatchOnce(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		d.reportError(err)
+
+		// a full batch means there may be more messages waiting
+		if sent == d.opt.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		case <-time.After(d.opt.PollInterval):
+		}
+	}
+}
+
+// Start - Run the dispatcher on a goroutine until Stop is called
+func (d *OutboxDispatcher) Start() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cancel != nil {
+		return errors.New("gmongo: outbox dispatcher is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		_ = d.Run(ctx)
+	}(d.done)
+
+	return nil
+}
+
+// Stop - Stop the dispatcher and wait for the message being delivered
+func (d *OutboxDispatcher) Stop() {
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.cancel, d.done = nil, nil
+	d.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-done
+}
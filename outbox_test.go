@@ -0,0 +1,178 @@
+package gmongo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestOutboxDispatcher_backoff(t *testing.T) {
+	d := (&Client{}).NewOutboxDispatcher(nil, OutboxDispatcherOptions{
+		Backoff:    time.Second,
+		MaxBackoff: 10 * time.Second,
+	})
+
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 4*time.Second, d.backoff(3))
+	assert.Equal(t, 10*time.Second, d.backoff(5))
+	assert.Equal(t, 10*time.Second, d.backoff(100))
+}
+
+func TestOutbox(t *testing.T) {
+	client := testConnectToDb()
+	requireReplicaSet(t, client)
+
+	client.OutboxCollection = "test_outbox"
+	OutboxModel := MakeModel[*OutboxMessage](client.Database, client.OutboxCollection)
+
+	reset := func() {
+		_, _ = OutboxModel.DeleteMany(bson.M{})
+	}
+
+	t.Run("Publishes only committed messages", func(t *testing.T) {
+		reset()
+
+		_ = client.Transaction(func(tx *Tx) error {
+			return tx.Publish("user.created", bson.M{"name": "John"})
+		})
+		_ = client.Transaction(func(tx *Tx) error {
+			_ = tx.Publish("user.created", bson.M{"name": "Doe"})
+			return errors.New("rollback")
+		})
+
+		var names []string
+		d := client.NewOutboxDispatcher(PublisherFunc(func(ctx context.Context, msg *OutboxMessage) error {
+			var payload struct {
+				Name string `bson:"name"`
+			}
+			if err := msg.Decode(&payload); err != nil {
+				return err
+			}
+			names = append(names, payload.Name)
+			return nil
+		}), OutboxDispatcherOptions{})
+
+		sent, err := d.DispatchOnce(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		assert.Equal(t, []string{"John"}, names)
+
+		count, _ := OutboxModel.Count(bson.M{"status": OutboxSent})
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Dead-letters after MaxAttempts", func(t *testing.T) {
+		reset()
+
+		_ = client.Transaction(func(tx *Tx) error {
+			return tx.Publish("user.created", bson.M{"name": "John"})
+		})
+
+		d := client.NewOutboxDispatcher(PublisherFunc(func(ctx context.Context, msg *OutboxMessage) error {
+			return errors.New("broker down")
+		}), OutboxDispatcherOptions{MaxAttempts: 2, Backoff: time.Millisecond})
+
+		_, _ = d.DispatchOnce(context.TODO())
+		msg, _ := OutboxModel.FindOne(bson.M{})
+		assert.Equal(t, OutboxPending, msg.Status)
+		assert.Equal(t, 1, msg.Attempts)
+		assert.Equal(t, "broker down", msg.LastError)
+
+		time.Sleep(5 * time.Millisecond)
+		_, _ = d.DispatchOnce(context.TODO())
+		msg, _ = OutboxModel.FindOne(bson.M{})
+		assert.Equal(t, OutboxDead, msg.Status)
+		assert.Equal(t, 2, msg.Attempts)
+	})
+
+	t.Run("Does not count a delivery after the lock is lost", func(t *testing.T) {
+		reset()
+
+		_ = client.Transaction(func(tx *Tx) error {
+			return tx.Publish("user.created", bson.M{"name": "John"})
+		})
+
+		var errs []error
+		d := client.NewOutboxDispatcher(PublisherFunc(func(ctx context.Context, msg *OutboxMessage) error {
+			// another dispatcher claims the message once the lock expired
+			_, err := OutboxModel.UpdateOne(bson.M{"_id": msg.ID}, bson.M{"$set": bson.M{"lockedBy": "other"}})
+			return err
+		}), OutboxDispatcherOptions{OnError: func(err error) { errs = append(errs, err) }})
+
+		sent, err := d.DispatchOnce(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		assert.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], ErrOutboxLockLost)
+
+		msg, _ := OutboxModel.FindOne(bson.M{})
+		assert.Equal(t, OutboxPending, msg.Status)
+		assert.Equal(t, "other", msg.LockedBy)
+	})
+
+	t.Run("Concurrent dispatchers deliver each message once", func(t *testing.T) {
+		reset()
+
+		_ = client.Transaction(func(tx *Tx) error {
+			for i := 0; i < 50; i++ {
+				if err := tx.Publish("tick", bson.M{"i": i}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+		var mu sync.Mutex
+		delivered := map[string]int{}
+		publisher := PublisherFunc(func(ctx context.Context, msg *OutboxMessage) error {
+			mu.Lock()
+			delivered[msg.ID.Hex()]++
+			mu.Unlock()
+			return nil
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = client.NewOutboxDispatcher(publisher, OutboxDispatcherOptions{}).DispatchOnce(context.TODO())
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, delivered, 50)
+		for _, n := range delivered {
+			assert.Equal(t, 1, n)
+		}
+	})
+
+	t.Run("Start and Stop", func(t *testing.T) {
+		reset()
+
+		sent := make(chan string, 1)
+		d := client.NewOutboxDispatcher(PublisherFunc(func(ctx context.Context, msg *OutboxMessage) error {
+			sent <- msg.Topic
+			return nil
+		}), OutboxDispatcherOptions{PollInterval: 10 * time.Millisecond, ChangeStream: true})
+
+		assert.NoError(t, d.Start())
+		defer d.Stop()
+
+		_ = client.Transaction(func(tx *Tx) error {
+			return tx.Publish("user.deleted", bson.M{})
+		})
+
+		select {
+		case topic := <-sent:
+			assert.Equal(t, "user.deleted", topic)
+		case <-time.After(5 * time.Second):
+			t.Error("message was not dispatched")
+		}
+	})
+}
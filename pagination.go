@@ -1,17 +1,26 @@
 package gmongo
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"reflect"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type PaginatedMeta struct {
-	Total    int `json:"total"`
-	PerPage  int `json:"perPage"`
-	Page     int `json:"page"`
-	LastPage int `json:"lastPage"`
+	Total    int  `json:"total"`
+	PerPage  int  `json:"perPage"`
+	Page     int  `json:"page"`
+	LastPage int  `json:"lastPage"`
+	HasNext  bool `json:"hasNext"`
+	HasPrev  bool `json:"hasPrev"`
+	// From - Position of the first document of the page (1-based), 0 if the page is empty
+	From int `json:"from"`
+	// To - Position of the last document of the page (1-based), 0 if the page is empty
+	To int `json:"to"`
 }
 
 type Paginated[T any] struct {
@@ -19,8 +28,147 @@ type Paginated[T any] struct {
 	Data T             `json:"data"`
 }
 
+// DefaultPerPage - perPage used when a PaginationRequest has none
+var DefaultPerPage = 20
+
+// DefaultMaxPerPage - Upper bound of perPage when a PaginationRequest has no MaxPerPage.
+// The int based Paginate* functions are not capped.
+var DefaultMaxPerPage = 100
+
+// ErrInvalidPagination - Returned (wrapped in a *PaginationError) for invalid pagination input
+var ErrInvalidPagination = errors.New("invalid pagination")
+
+// PaginationError - A pagination input that cannot be normalized
+type PaginationError struct {
+	Field string
+	Value int
+}
+
+func (e *PaginationError) Error() string {
+	return fmt.Sprintf("gmongo: %s: %s must not be negative, got %d", ErrInvalidPagination, e.Field, e.Value)
+}
+
+func (e *PaginationError) Unwrap() error {
+	return ErrInvalidPagination
+}
+
+// IsPaginationError - Check if the error is a pagination input error
+func IsPaginationError(err error) bool {
+	return errors.Is(err, ErrInvalidPagination)
+}
+
+// PaginationRequest - Pagination input shared by all paginate functions
+type PaginationRequest struct {
+	Page    int
+	PerPage int
+	// Sort - Optional sort applied before skip and limit
+	Sort bson.D
+	// MaxPerPage - Upper bound of PerPage, defaults to DefaultMaxPerPage
+	MaxPerPage int
+}
+
+// Normalize - Clamp the request to valid values.
+//
+// A zero Page becomes 1, a zero PerPage becomes DefaultPerPage and PerPage is
+// capped at MaxPerPage. Negative values return a *PaginationError.
+func (r PaginationRequest) Normalize() (PaginationRequest, error) {
+	if r.Page < 0 {
+		return r, &PaginationError{Field: "page", Value: r.Page}
+	}
+	if r.PerPage < 0 {
+		return r, &PaginationError{Field: "perPage", Value: r.PerPage}
+	}
+
+	if r.Page == 0 {
+		r.Page = 1
+	}
+	if r.MaxPerPage <= 0 {
+		r.MaxPerPage = DefaultMaxPerPage
+	}
+	if r.PerPage == 0 {
+		r.PerPage = DefaultPerPage
+	}
+	if r.PerPage > r.MaxPerPage {
+		r.PerPage = r.MaxPerPage
+	}
+
+	return r, nil
+}
+
+// pageRequest - The PaginationRequest of the int based Paginate* functions,
+// which predate MaxPerPage so their perPage is not capped
+func pageRequest(page int, perPage int) PaginationRequest {
+	return PaginationRequest{Page: page, PerPage: perPage, MaxPerPage: math.MaxInt}
+}
+
+// Skip - Number of documents before the requested page
+func (r PaginationRequest) Skip() int {
+	return (r.Page - 1) * r.PerPage
+}
+
+// newPaginatedMeta - Build pagination meta from a total count
+func newPaginatedMeta(total int64, page int, perPage int) PaginatedMeta {
+	meta := PaginatedMeta{
+		Total:   int(total),
+		PerPage: perPage,
+		Page:    page,
+		HasPrev: page > 1,
+	}
+
+	if total == 0 || perPage <= 0 {
+		return meta
+	}
+
+	// ceil total/perPage
+	meta.LastPage = int(math.Ceil(float64(total) / float64(perPage)))
+	meta.HasNext = page < meta.LastPage
+
+	if page <= meta.LastPage {
+		meta.From = (page-1)*perPage + 1
+		meta.To = min(page*perPage, int(total))
+	}
+
+	return meta
+}
+
+// emptyPage - A page without results
+func emptyPage(req PaginationRequest) *Paginated[any] {
+	return &Paginated[any]{
+		Meta: newPaginatedMeta(0, req.Page, req.PerPage),
+		Data: []bson.M{},
+	}
+}
+
+// withSort - Append a $sort stage to a copy of query if the request has a sort
+func (r PaginationRequest) withSort(query []interface{}) []interface{} {
+	res := make([]interface{}, 0, len(query)+1)
+	res = append(res, query...)
+	if len(r.Sort) > 0 {
+		res = append(res, bson.M{"$sort": r.Sort})
+	}
+	return res
+}
+
 // PaginateAggregateWithCountQuery - Paginate aggregate with count query
-func (coll *Model[T]) PaginateAggregateWithCountQuery(page int, perPage int, countQuery interface{}, query []interface{}) (*Paginated[any], error) {
+func (coll *ModelOf[T, ID]) PaginateAggregateWithCountQuery(page int, perPage int, countQuery interface{}, query []interface{}) (*Paginated[any], error) {
+	return coll.paginateAggregate(pageRequest(page, perPage), countQuery, query)
+}
+
+func (coll *ModelOf[T, ID]) PaginateAggregate(page int, perPage int, query []interface{}) (*Paginated[any], error) {
+	return coll.PaginateAggregateWithCountQuery(page, perPage, nil, query)
+}
+
+// PaginateAggregateWith - Paginate aggregate using a PaginationRequest
+func (coll *ModelOf[T, ID]) PaginateAggregateWith(req PaginationRequest, query []interface{}) (*Paginated[any], error) {
+	return coll.paginateAggregate(req, nil, req.withSort(query))
+}
+
+func (coll *ModelOf[T, ID]) paginateAggregate(req PaginationRequest, countQuery interface{}, query []interface{}) (*Paginated[any], error) {
+	req, err := req.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
 	// get total count
 	totalCount := int64(0)
 	if countQuery != nil {
@@ -41,24 +189,12 @@ func (coll *Model[T]) PaginateAggregateWithCountQuery(page int, perPage int, cou
 
 	// if no results
 	if totalCount == 0 {
-		return &Paginated[any]{
-			Meta: PaginatedMeta{
-				Total:    0,
-				PerPage:  perPage,
-				Page:     page,
-				LastPage: 0,
-			},
-			Data: []bson.M{},
-		}, nil
+		return emptyPage(req), nil
 	}
 
-	// ceil total/perPage
-	lastPage := int(math.Ceil(float64(totalCount) / float64(perPage)))
-	skip := (page - 1) * perPage
-
 	// add skip and limit to query
-	query = append(query, bson.M{"$skip": skip})
-	query = append(query, bson.M{"$limit": perPage})
+	query = append(query, bson.M{"$skip": req.Skip()})
+	query = append(query, bson.M{"$limit": req.PerPage})
 
 	// find
 	cursor, err := coll.Native().Aggregate(
@@ -72,93 +208,135 @@ func (coll *Model[T]) PaginateAggregateWithCountQuery(page int, perPage int, cou
 
 	// get results
 	var results = make([]bson.M, 0)
-	if err = cursor.All(coll.ctx(), &results); err != nil {
+	if err = coll.decodeAll(cursor, &results); err != nil {
 		return nil, err
 	}
 
 	return &Paginated[any]{
-		Meta: PaginatedMeta{
-			Total:    int(totalCount),
-			PerPage:  perPage,
-			Page:     page,
-			LastPage: lastPage,
-		},
+		Meta: newPaginatedMeta(totalCount, req.Page, req.PerPage),
 		Data: results,
 	}, nil
 }
 
-func (coll *Model[T]) PaginateAggregate(page int, perPage int, query []interface{}) (*Paginated[any], error) {
-	return coll.PaginateAggregateWithCountQuery(page, perPage, nil, query)
-}
-
 // Paginate - Paginate Find
-func (coll *Model[T]) Paginate(
+func (coll *ModelOf[T, ID]) Paginate(
 	page int,
 	perPage int,
 	query interface{},
 	opts ...*options.FindOptions,
 ) (*Paginated[any], error) {
+	return coll.PaginateWith(pageRequest(page, perPage), query, opts...)
+}
+
+// PaginateWith - Paginate Find using a PaginationRequest
+func (coll *ModelOf[T, ID]) PaginateWith(req PaginationRequest, query interface{}, opts ...*options.FindOptions) (*Paginated[any], error) {
+	req, query, totalCount, err := coll.countFind(req, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return coll.paginateFind(req, query, totalCount, opts...)
+}
+
+// countFind - Normalize req, encrypt query and count the documents it matches
+func (coll *ModelOf[T, ID]) countFind(req PaginationRequest, query interface{}) (PaginationRequest, interface{}, int64, error) {
+	req, err := req.Normalize()
+	if err != nil {
+		return req, nil, 0, err
+	}
+
+	query, err = coll.encryptFilter(query)
+	if err != nil {
+		return req, nil, 0, err
+	}
+
 	// get total count
 	totalCount, err := coll.Native().CountDocuments(coll.ctx(), query)
 	if err != nil {
-		return nil, err
+		return req, nil, 0, err
 	}
 
+	return req, query, totalCount, nil
+}
+
+// paginateFind - Find a page of documents once the total count is known
+func (coll *ModelOf[T, ID]) paginateFind(
+	req PaginationRequest,
+	query interface{},
+	totalCount int64,
+	opts ...*options.FindOptions,
+) (*Paginated[any], error) {
 	// if no results
 	if totalCount == 0 {
-		return &Paginated[any]{
-			Meta: PaginatedMeta{
-				Total:    0,
-				PerPage:  perPage,
-				Page:     page,
-				LastPage: 0,
-			},
-			Data: []bson.M{},
-		}, nil
+		return emptyPage(req), nil
 	}
 
-	// ceil total/perPage
-	lastPage := int(math.Ceil(float64(totalCount) / float64(perPage)))
-	skip := (page - 1) * perPage
+	var results = make([]bson.M, 0)
+	if err := coll.findPage(req, query, &results, opts...); err != nil {
+		return nil, err
+	}
 
+	return &Paginated[any]{
+		Meta: newPaginatedMeta(totalCount, req.Page, req.PerPage),
+		Data: results,
+	}, nil
+}
+
+// findPage - Find the documents of a page and decode them into results, a pointer to a slice
+func (coll *ModelOf[T, ID]) findPage(req PaginationRequest, query interface{}, results interface{}, opts ...*options.FindOptions) error {
 	// build options
-	opts = append(opts, options.Find().SetSkip(int64(skip)).SetLimit(int64(perPage)))
+	if len(req.Sort) > 0 {
+		opts = append(opts, options.Find().SetSort(req.Sort))
+	}
+	opts = append(opts, options.Find().SetSkip(int64(req.Skip())).SetLimit(int64(req.PerPage)))
 
 	// find
 	cursor, err := coll.Native().Find(coll.ctx(), query, opts...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// get results
-	var results = make([]bson.M, 0)
-	if err = cursor.All(coll.ctx(), &results); err != nil {
-		return nil, err
-	}
-
-	return &Paginated[any]{
-		Meta: PaginatedMeta{
-			Total:    int(totalCount),
-			PerPage:  perPage,
-			Page:     page,
-			LastPage: lastPage,
-		},
-		Data: results,
-	}, nil
+	return coll.decodeAll(cursor, results)
 }
 
+// PaginateCount - How the total of a paginated query is computed
+type PaginateCount int
+
+const (
+	// PaginateCountQuery - Count with a separate count query (default)
+	PaginateCountQuery PaginateCount = iota
+	// PaginateCountFacet - Count and fetch the page in a single $facet aggregation
+	PaginateCountFacet
+	// PaginateCountEstimated - Use the estimated document count of the collection.
+	// A non-empty Match falls back to counting the matching documents.
+	PaginateCountEstimated
+)
+
 type PaginateAggregateOptions struct {
 	Match       interface{}
 	BeforeLimit []bson.M
 	AfterLimit  []bson.M
 	Total       *int64
+	Count       PaginateCount
 }
 
 // PaginateAggregateRaw - Paginate aggregate raw
 // About: This function came about when I noticed the poor performance of the `PaginateAggregate` function when running
 // lookups. This is because the limit and skip are applied after the lookup which is not efficient or not always the best
 // way to paginate. This function allows you to paginate with the limit and skip applied before the lookup.
-func (coll *Model[T]) PaginateAggregateRaw(page int, perPage int, Opt *PaginateAggregateOptions) (*Paginated[any], error) {
+func (coll *ModelOf[T, ID]) PaginateAggregateRaw(page int, perPage int, Opt *PaginateAggregateOptions) (*Paginated[any], error) {
+	return coll.PaginateAggregateRawWith(pageRequest(page, perPage), Opt)
+}
+
+// PaginateAggregateRawWith - PaginateAggregateRaw using a PaginationRequest.
+// The request sort is applied before BeforeLimit.
+func (coll *ModelOf[T, ID]) PaginateAggregateRawWith(req PaginationRequest, Opt *PaginateAggregateOptions) (*Paginated[any], error) {
+	req, err := req.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
 	var opt PaginateAggregateOptions
 	if Opt != nil {
 		opt = *Opt
@@ -168,10 +346,25 @@ func (coll *Model[T]) PaginateAggregateRaw(page int, perPage int, Opt *PaginateA
 		opt.Match = bson.M{}
 	}
 
+	if len(req.Sort) > 0 {
+		opt.BeforeLimit = append([]bson.M{{"$sort": req.Sort}}, opt.BeforeLimit...)
+	}
+
+	if opt.Total == nil && opt.Count == PaginateCountFacet {
+		return coll.paginateAggregateRawFacet(req, opt)
+	}
+
 	// get total count
 	totalCount := int64(0)
 	if opt.Total != nil {
 		totalCount = *opt.Total
+	} else if opt.Count == PaginateCountEstimated && isEmptyFilter(opt.Match) {
+		count, err := coll.Native().EstimatedDocumentCount(coll.ctx())
+		if err != nil {
+			return nil, err
+		}
+
+		totalCount = count
 	} else {
 		count, err := coll.Count(opt.Match)
 		if err != nil {
@@ -183,27 +376,15 @@ func (coll *Model[T]) PaginateAggregateRaw(page int, perPage int, Opt *PaginateA
 
 	// if no results
 	if totalCount == 0 {
-		return &Paginated[any]{
-			Meta: PaginatedMeta{
-				Total:    0,
-				PerPage:  perPage,
-				Page:     page,
-				LastPage: 0,
-			},
-			Data: []bson.M{},
-		}, nil
+		return emptyPage(req), nil
 	}
 
-	// ceil total/perPage
-	lastPage := int(math.Ceil(float64(totalCount) / float64(perPage)))
-	skip := (page - 1) * perPage
-
 	// add skip and limit to query
 	query := make([]bson.M, 0)
 	query = append(query, bson.M{"$match": opt.Match})
 	query = append(query, opt.BeforeLimit...)
-	query = append(query, bson.M{"$skip": skip})
-	query = append(query, bson.M{"$limit": perPage})
+	query = append(query, bson.M{"$skip": req.Skip()})
+	query = append(query, bson.M{"$limit": req.PerPage})
 	query = append(query, opt.AfterLimit...)
 
 	// find
@@ -218,17 +399,248 @@ func (coll *Model[T]) PaginateAggregateRaw(page int, perPage int, Opt *PaginateA
 
 	// get results
 	var results = make([]bson.M, 0)
-	if err = cursor.All(coll.ctx(), &results); err != nil {
+	if err = coll.decodeAll(cursor, &results); err != nil {
 		return nil, err
 	}
 
 	return &Paginated[any]{
-		Meta: PaginatedMeta{
-			Total:    int(totalCount),
-			PerPage:  perPage,
-			Page:     page,
-			LastPage: lastPage,
-		},
+		Meta: newPaginatedMeta(totalCount, req.Page, req.PerPage),
 		Data: results,
 	}, nil
 }
+
+// isEmptyFilter - Check if a filter matches all documents
+func isEmptyFilter(filter interface{}) bool {
+	if filter == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(filter)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil() || isEmptyFilter(v.Elem().Interface())
+	case reflect.Map, reflect.Slice, reflect.Array:
+		if raw, ok := filter.(bson.Raw); ok {
+			elements, err := raw.Elements()
+			return err == nil && len(elements) == 0
+		}
+		return v.Len() == 0
+	}
+	return false
+}
+
+// FacetCount - Number of documents having a value of a facet field
+type FacetCount struct {
+	Value interface{} `json:"value" bson:"_id"`
+	Count int64       `json:"count" bson:"count"`
+}
+
+// FacetedPaginated - A page of documents with value counts of facet fields
+type FacetedPaginated struct {
+	Paginated[any]
+	Facets map[string][]FacetCount `json:"facets"`
+}
+
+// paginateAggregateRawFacet - PaginateAggregateRaw counting in the same $facet aggregation
+func (coll *ModelOf[T, ID]) paginateAggregateRawFacet(req PaginationRequest, opt PaginateAggregateOptions) (*Paginated[any], error) {
+	dataStages := make([]interface{}, 0, len(opt.BeforeLimit)+len(opt.AfterLimit)+2)
+	for _, stage := range opt.BeforeLimit {
+		dataStages = append(dataStages, stage)
+	}
+	dataStages = append(dataStages, bson.M{"$skip": req.Skip()}, bson.M{"$limit": req.PerPage})
+	for _, stage := range opt.AfterLimit {
+		dataStages = append(dataStages, stage)
+	}
+
+	totalCount, data, _, err := coll.aggregatePage(
+		[]interface{}{bson.M{"$match": opt.Match}},
+		dataStages,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Paginated[any]{
+		Meta: newPaginatedMeta(totalCount, req.Page, req.PerPage),
+		Data: data,
+	}, nil
+}
+
+// aggregatePage - Run pipeline followed by a single $facet stage that counts all
+// documents and returns the page produced by dataStages, along with any extra facets.
+// The raw facet document is returned so callers can read their extra facets from it.
+func (coll *ModelOf[T, ID]) aggregatePage(pipeline []interface{}, dataStages []interface{}, extra bson.M) (int64, []bson.M, bson.Raw, error) {
+	facet := bson.M{
+		"data":  dataStages,
+		"total": bson.A{bson.M{"$count": "count"}},
+	}
+	for name, stages := range extra {
+		facet[name] = stages
+	}
+
+	query := make([]interface{}, 0, len(pipeline)+1)
+	query = append(query, pipeline...)
+	query = append(query, bson.M{"$facet": facet})
+
+	var res []bson.Raw
+	if err := coll.AggregateAs(&res, query); err != nil {
+		return 0, nil, nil, err
+	}
+
+	data := make([]bson.M, 0)
+	if len(res) == 0 {
+		return 0, data, nil, nil
+	}
+	registry := coll.registry()
+
+	// total
+	var total []struct {
+		Count int64 `bson:"count"`
+	}
+	if err := res[0].Lookup("total").UnmarshalWithRegistry(registry, &total); err != nil {
+		return 0, nil, nil, err
+	}
+
+	totalCount := int64(0)
+	if len(total) > 0 {
+		totalCount = total[0].Count
+	}
+
+	// data, decrypted when encryption is enabled
+	if coll.encryption == nil {
+		if err := res[0].Lookup("data").UnmarshalWithRegistry(registry, &data); err != nil {
+			return 0, nil, nil, err
+		}
+	} else {
+		var raws []bson.Raw
+		if err := res[0].Lookup("data").UnmarshalWithRegistry(registry, &raws); err != nil {
+			return 0, nil, nil, err
+		}
+		if err := coll.encrypter().decryptAll(raws, &data); err != nil {
+			return 0, nil, nil, err
+		}
+	}
+
+	return totalCount, data, res[0], nil
+}
+
+// Facets - Paginate documents and count values of facet fields in a single $facet query.
+//
+// Facet counts are computed over all documents matching the filter, not just the page,
+// and are sorted by count in descending order.
+//
+// Example:
+//
+//	res, _ := ProductModel.Facets(bson.M{"active": true}, []string{"category", "status"}, 1, 20)
+//	// res.Data is the first 20 products
+//	// res.Facets["category"] will be [{Value: "shoes", Count: 12}, {Value: "bags", Count: 4}]
+func (coll *ModelOf[T, ID]) Facets(filter interface{}, facetFields []string, page int, perPage int) (*FacetedPaginated, error) {
+	return coll.FacetsWith(pageRequest(page, perPage), filter, facetFields)
+}
+
+// FacetsWith - Facets using a PaginationRequest
+func (coll *ModelOf[T, ID]) FacetsWith(req PaginationRequest, filter interface{}, facetFields []string) (*FacetedPaginated, error) {
+	req, err := req.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	if filter == nil {
+		filter = bson.M{}
+	}
+	if filter, err = coll.encryptFilter(filter); err != nil {
+		return nil, err
+	}
+
+	extra := bson.M{}
+	for i, field := range facetFields {
+		extra[fmt.Sprintf("f%d", i)] = bson.A{bson.M{"$sortByCount": fmt.Sprintf("$%s", field)}}
+	}
+
+	totalCount, data, raw, err := coll.aggregatePage(
+		req.withSort([]interface{}{bson.M{"$match": filter}}),
+		[]interface{}{bson.M{"$skip": req.Skip()}, bson.M{"$limit": req.PerPage}},
+		extra,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FacetedPaginated{
+		Paginated: Paginated[any]{Meta: newPaginatedMeta(totalCount, req.Page, req.PerPage), Data: data},
+		Facets:    make(map[string][]FacetCount, len(facetFields)),
+	}
+
+	registry := coll.registry()
+	for i, field := range facetFields {
+		counts := make([]FacetCount, 0)
+		if raw != nil {
+			if err := raw.Lookup(fmt.Sprintf("f%d", i)).UnmarshalWithRegistry(registry, &counts); err != nil {
+				return nil, err
+			}
+		}
+		for j := range counts {
+			if counts[j].Value, err = coll.decryptValue(counts[j].Value); err != nil {
+				return nil, err
+			}
+		}
+		result.Facets[field] = counts
+	}
+
+	return result, nil
+}
+
+// PaginateFacet - Paginate aggregate in a single round trip.
+//
+// Unlike PaginateAggregate, the total and the page are computed by the same $facet
+// aggregation, so the total always agrees with the page even under concurrent writes.
+// The page must fit in a single 16MB document.
+func (coll *ModelOf[T, ID]) PaginateFacet(page int, perPage int, query []interface{}) (*Paginated[any], error) {
+	return coll.PaginateFacetWith(pageRequest(page, perPage), query)
+}
+
+// PaginateFacetWith - PaginateFacet using a PaginationRequest
+func (coll *ModelOf[T, ID]) PaginateFacetWith(req PaginationRequest, query []interface{}) (*Paginated[any], error) {
+	req, err := req.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, data, _, err := coll.aggregatePage(
+		req.withSort(query),
+		[]interface{}{bson.M{"$skip": req.Skip()}, bson.M{"$limit": req.PerPage}},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Paginated[any]{
+		Meta: newPaginatedMeta(totalCount, req.Page, req.PerPage),
+		Data: data,
+	}, nil
+}
+
+// PaginateEstimated - Paginate all documents of the collection using the estimated document count.
+//
+// EstimatedDocumentCount reads the collection metadata instead of counting documents,
+// which makes it much faster on large collections but only usable for unfiltered listings.
+func (coll *ModelOf[T, ID]) PaginateEstimated(page int, perPage int, opts ...*options.FindOptions) (*Paginated[any], error) {
+	return coll.PaginateEstimatedWith(pageRequest(page, perPage), opts...)
+}
+
+// PaginateEstimatedWith - PaginateEstimated using a PaginationRequest
+func (coll *ModelOf[T, ID]) PaginateEstimatedWith(req PaginationRequest, opts ...*options.FindOptions) (*Paginated[any], error) {
+	req, err := req.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, err := coll.Native().EstimatedDocumentCount(coll.ctx())
+	if err != nil {
+		return nil, err
+	}
+
+	return coll.paginateFind(req, bson.M{}, totalCount, opts...)
+}
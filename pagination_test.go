@@ -0,0 +1,84 @@
+package gmongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_newPaginatedMeta(t *testing.T) {
+	assert.Equal(t, PaginatedMeta{
+		Total: 11, PerPage: 5, Page: 2, LastPage: 3,
+		HasNext: true, HasPrev: true, From: 6, To: 10,
+	}, newPaginatedMeta(11, 2, 5))
+
+	assert.Equal(t, PaginatedMeta{
+		Total: 11, PerPage: 5, Page: 3, LastPage: 3,
+		HasNext: false, HasPrev: true, From: 11, To: 11,
+	}, newPaginatedMeta(11, 3, 5))
+
+	// page past the last page is empty
+	assert.Equal(t, PaginatedMeta{
+		Total: 11, PerPage: 5, Page: 4, LastPage: 3,
+		HasNext: false, HasPrev: true, From: 0, To: 0,
+	}, newPaginatedMeta(11, 4, 5))
+
+	assert.Equal(t, PaginatedMeta{Total: 0, PerPage: 5, Page: 1, LastPage: 0}, newPaginatedMeta(0, 1, 5))
+}
+
+func Test_PaginationRequest_Normalize(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		req, err := PaginationRequest{}.Normalize()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, req.Page)
+		assert.Equal(t, DefaultPerPage, req.PerPage)
+		assert.Equal(t, DefaultMaxPerPage, req.MaxPerPage)
+		assert.Equal(t, 0, req.Skip())
+	})
+
+	t.Run("Clamps PerPage", func(t *testing.T) {
+		req, err := PaginationRequest{Page: 3, PerPage: 500, MaxPerPage: 50}.Normalize()
+		assert.NoError(t, err)
+		assert.Equal(t, 50, req.PerPage)
+		assert.Equal(t, 100, req.Skip())
+	})
+
+	t.Run("Int based requests are not capped", func(t *testing.T) {
+		req, err := pageRequest(2, 500).Normalize()
+		assert.NoError(t, err)
+		assert.Equal(t, 500, req.PerPage)
+		assert.Equal(t, 500, req.Skip())
+
+		req, err = pageRequest(0, 0).Normalize()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, req.Page)
+		assert.Equal(t, DefaultPerPage, req.PerPage)
+	})
+
+	t.Run("Rejects negative values", func(t *testing.T) {
+		_, err := PaginationRequest{Page: -1, PerPage: 10}.Normalize()
+		assert.True(t, IsPaginationError(err))
+
+		_, err = PaginationRequest{Page: 1, PerPage: -10}.Normalize()
+		assert.True(t, IsPaginationError(err))
+
+		var pErr *PaginationError
+		assert.ErrorAs(t, err, &pErr)
+		assert.Equal(t, "perPage", pErr.Field)
+	})
+}
+
+func Test_isEmptyFilter(t *testing.T) {
+	assert.True(t, isEmptyFilter(nil))
+	assert.True(t, isEmptyFilter(bson.M{}))
+	assert.True(t, isEmptyFilter(bson.D{}))
+	assert.True(t, isEmptyFilter(&bson.M{}))
+	raw, _ := bson.Marshal(bson.M{})
+	assert.True(t, isEmptyFilter(bson.Raw(raw)))
+
+	assert.False(t, isEmptyFilter(bson.M{"verified": true}))
+	assert.False(t, isEmptyFilter(bson.D{{Key: "verified", Value: true}}))
+	raw, _ = bson.Marshal(bson.M{"verified": true})
+	assert.False(t, isEmptyFilter(bson.Raw(raw)))
+}
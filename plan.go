@@ -0,0 +1,110 @@
+package gmongo
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fieldPlan - How a struct field is named by a tag
+type fieldPlan struct {
+	index     int
+	name      string
+	omitEmpty bool
+	inline    bool
+	typ       reflect.Type
+	// hasStructs - Values of the field may contain fields to map or pick, see hasStructs
+	hasStructs bool
+}
+
+// structPlan - The fields of a struct type named by a tag, computed once per type and tag
+type structPlan struct {
+	fields []fieldPlan
+	// byIndex - Position in fields by struct field index
+	byIndex map[int]int
+	// hidden - Struct field indexes tagged "-"
+	hidden map[int]bool
+}
+
+type planKey struct {
+	typ reflect.Type
+	tag string
+}
+
+// structPlans - Cache of structPlan by planKey
+var structPlans sync.Map
+
+// planOf - Get the plan of a struct type for a tag. Fields without a name in
+// the tag are skipped, unless they are inline.
+func planOf(t reflect.Type, tag string) *structPlan {
+	key := planKey{typ: t, tag: tag}
+	if plan, ok := structPlans.Load(key); ok {
+		return plan.(*structPlan)
+	}
+
+	plan := &structPlan{byIndex: map[int]int{}, hidden: map[int]bool{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts := parseTag(field.Tag.Get(tag))
+
+		inline := hasTagOpt(opts, "inline")
+		if inline {
+			inner := field.Type
+			if inner.Kind() == reflect.Ptr {
+				inner = inner.Elem()
+			}
+			if inner.Kind() != reflect.Struct {
+				continue
+			}
+		} else if name == "-" {
+			plan.hidden[i] = true
+			continue
+		} else if name == "" {
+			continue
+		}
+
+		plan.byIndex[i] = len(plan.fields)
+		plan.fields = append(plan.fields, fieldPlan{
+			index:      i,
+			name:       name,
+			omitEmpty:  hasTagOpt(opts, "omitempty"),
+			inline:     inline,
+			typ:        field.Type,
+			hasStructs: !inline && hasStructs(field.Type),
+		})
+	}
+
+	actual, _ := structPlans.LoadOrStore(key, plan)
+	return actual.(*structPlan)
+}
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	primitivePkg       = reflect.TypeOf(primitive.ObjectID{}).PkgPath()
+	valueMarshalerType = reflect.TypeOf((*bson.ValueMarshaler)(nil)).Elem()
+	bsonMarshalerType  = reflect.TypeOf((*bson.Marshaler)(nil)).Elem()
+	jsonMarshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerIface = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// isLeafStruct - Check if a struct type is serialized as a single value
+// (time.Time, primitive.*, and types with their own marshaller) rather than
+// field by field
+func isLeafStruct(t reflect.Type) bool {
+	if t == timeType || t.PkgPath() == primitivePkg {
+		return true
+	}
+
+	ptr := reflect.PointerTo(t)
+	for _, iface := range []reflect.Type{valueMarshalerType, bsonMarshalerType, jsonMarshalerType, textMarshalerIface} {
+		if t.Implements(iface) || ptr.Implements(iface) {
+			return true
+		}
+	}
+	return false
+}
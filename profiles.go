@@ -0,0 +1,135 @@
+package gmongo
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Visibility profiles commonly defined in Model[T].Profiles
+const (
+	ProfilePublic = "public"
+	ProfileOwner  = "owner"
+	ProfileAdmin  = "admin"
+)
+
+// ProfileAll - A profile field selecting every field, e.g. Profiles["admin"] = []string{gmongo.ProfileAll}
+const ProfileAll = "*"
+
+type profileContextKey struct{}
+
+// WithProfile - Return a copy of ctx selecting a visibility profile, see Model[T].ContextProfile
+//
+//	ctx = gmongo.WithProfile(ctx, gmongo.ProfileOwner)
+//	data := UserModel.WithContext(ctx).GetContextProfileFields(user)
+func WithProfile(ctx context.Context, profile string) context.Context {
+	return context.WithValue(ctx, profileContextKey{}, profile)
+}
+
+// ProfileFromContext - Get the profile set by WithProfile, empty if none
+func ProfileFromContext(ctx context.Context) string {
+	profile, _ := ctx.Value(profileContextKey{}).(string)
+	return profile
+}
+
+// SetProfile - Define the bson paths visible to a profile. Paths starting with
+// "@" include the paths of another profile, ProfileAll selects every field.
+//
+//	UserModel.SetProfile(gmongo.ProfileOwner, "@public", "email", "settings")
+//	UserModel.SetProfile(gmongo.ProfileAdmin, gmongo.ProfileAll)
+func (coll *ModelOf[T, ID]) SetProfile(profile string, fields ...string) {
+	if coll.Profiles == nil {
+		coll.Profiles = map[string][]string{}
+	}
+	coll.Profiles[profile] = fields
+}
+
+// ProfileFields - Get the bson paths visible to a profile, with includes expanded.
+// The public profile defaults to PublicFields; unknown profiles see nothing.
+func (coll *ModelOf[T, ID]) ProfileFields(profile string) []string {
+	return coll.profileFields(profile, map[string]bool{})
+}
+
+// profileFields - Expand the fields of a profile, skipping profiles already seen
+func (coll *ModelOf[T, ID]) profileFields(profile string, seen map[string]bool) []string {
+	if seen[profile] {
+		return nil
+	}
+	seen[profile] = true
+
+	fields, ok := coll.Profiles[profile]
+	if !ok && profile == ProfilePublic {
+		fields = coll.PublicFields
+	}
+
+	res := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if include, ok := strings.CutPrefix(field, "@"); ok {
+			res = append(res, coll.profileFields(include, seen)...)
+			continue
+		}
+		res = append(res, field)
+	}
+	return res
+}
+
+// ContextProfile - Get the profile selected by the context of the model (see
+// WithContext and WithProfile), ProfilePublic if none
+func (coll *ModelOf[T, ID]) ContextProfile() string {
+	if profile := ProfileFromContext(coll.ctx()); profile != "" {
+		return profile
+	}
+	return ProfilePublic
+}
+
+// noFieldsProjection - A field that is never stored, projecting only it
+// returns empty documents. {"_id": 0} alone would exclude _id and return every
+// other field.
+const noFieldsProjection = "__none"
+
+// ProjectProfile - Project the fields visible to a profile. Unknown profiles
+// and profiles without fields project nothing.
+func (coll *ModelOf[T, ID]) ProjectProfile(profile string) bson.M {
+	fields := coll.ProfileFields(profile)
+	for _, field := range fields {
+		if field == ProfileAll {
+			return bson.M{}
+		}
+	}
+	if len(fields) == 0 {
+		return bson.M{"_id": 0, noFieldsProjection: 1}
+	}
+	return Projection.OmitIdAndPick(fields)
+}
+
+// ProjectContextProfile - Project the fields visible to the profile of the context, see ContextProfile
+func (coll *ModelOf[T, ID]) ProjectContextProfile() bson.M {
+	return coll.ProjectProfile(coll.ContextProfile())
+}
+
+// GetProfileFields - Get the fields of a model visible to a profile
+func (coll *ModelOf[T, ID]) GetProfileFields(model interface{}, profile string) bson.M {
+	fields := coll.ProfileFields(profile)
+	for _, field := range fields {
+		if field == ProfileAll {
+			return pickTree(model, nil, coll.publicKeyTag())
+		}
+	}
+	return pickPaths(model, fields, coll.publicKeyTag())
+}
+
+// GetContextProfileFields - Get the fields of a model visible to the profile of the context, see ContextProfile
+func (coll *ModelOf[T, ID]) GetContextProfileFields(model interface{}) bson.M {
+	return coll.GetProfileFields(model, coll.ContextProfile())
+}
+
+// GetProfileFields - Get the fields of a model instance visible to a profile
+func (m ModelHelperOf[T, ID]) GetProfileFields(profile string) bson.M {
+	return m.Model.GetProfileFields(*m.Data, profile)
+}
+
+// GetContextProfileFields - Get the fields of a model instance visible to the profile of the context
+func (m ModelHelperOf[T, ID]) GetContextProfileFields() bson.M {
+	return m.Model.GetContextProfileFields(*m.Data)
+}
@@ -0,0 +1,55 @@
+package gmongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestModel_Profiles(t *testing.T) {
+	UserModel := CreateModel[*User]("users")
+	UserModel.PublicFields = []string{"name"}
+	UserModel.SetProfile(ProfileOwner, "@public", "age")
+	UserModel.SetProfile(ProfileAdmin, ProfileAll)
+	// includes are expanded once
+	UserModel.SetProfile("loop", "@loop", "verified")
+
+	user := &User{ID: primitive.NewObjectID(), Name: "John", Age: 20, Verified: true}
+
+	t.Run("ProfileFields", func(t *testing.T) {
+		assert.Equal(t, []string{"name"}, UserModel.ProfileFields(ProfilePublic))
+		assert.Equal(t, []string{"name", "age"}, UserModel.ProfileFields(ProfileOwner))
+		assert.Equal(t, []string{"verified"}, UserModel.ProfileFields("loop"))
+		assert.Empty(t, UserModel.ProfileFields("unknown"))
+	})
+
+	t.Run("ProjectProfile", func(t *testing.T) {
+		assert.Equal(t, UserModel.ProjectPublicFields(), UserModel.ProjectProfile(ProfilePublic))
+		assert.Equal(t, bson.M{"_id": 0, "name": 1, "age": 1}, UserModel.ProjectProfile(ProfileOwner))
+		assert.Equal(t, bson.M{}, UserModel.ProjectProfile(ProfileAdmin))
+		// nothing is visible, rather than everything but _id
+		assert.Equal(t, bson.M{"_id": 0, "__none": 1}, UserModel.ProjectProfile("unknown"))
+		assert.Equal(t, bson.M{"_id": 0, "__none": 1}, UserModel.ProjectProfile(""))
+	})
+
+	t.Run("GetProfileFields", func(t *testing.T) {
+		assert.Equal(t, bson.M{"name": "John"}, UserModel.GetProfileFields(user, ProfilePublic))
+		assert.Equal(t, bson.M{"name": "John", "age": 20}, UserModel.GetProfileFields(user, ProfileOwner))
+		assert.Equal(t, bson.M{"_id": user.ID, "name": "John", "age": 20, "verified": true}, UserModel.GetProfileFields(user, ProfileAdmin))
+		assert.Equal(t, bson.M{}, UserModel.GetProfileFields(user, "unknown"))
+		assert.Equal(t, UserModel.GetProfileFields(user, ProfileOwner), UserModel.Helpers(user).GetProfileFields(ProfileOwner))
+	})
+
+	t.Run("Profile from context", func(t *testing.T) {
+		assert.Equal(t, ProfilePublic, UserModel.ContextProfile())
+		assert.Equal(t, bson.M{"name": "John"}, UserModel.GetContextProfileFields(user))
+
+		owner := UserModel.WithContext(WithProfile(context.TODO(), ProfileOwner))
+		assert.Equal(t, ProfileOwner, owner.ContextProfile())
+		assert.Equal(t, bson.M{"_id": 0, "name": 1, "age": 1}, owner.ProjectContextProfile())
+		assert.Equal(t, bson.M{"name": "John", "age": 20}, owner.Helpers(user).GetContextProfileFields())
+	})
+}
@@ -0,0 +1,141 @@
+package gmongo
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// projections - Cache of projectionOf by type
+var projections sync.Map
+
+// projectionOf - Get the projection of the bson fields of a struct type, nil
+// if it has none or inlines a map. _id is excluded unless the struct has it.
+func projectionOf(t reflect.Type) bson.M {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if projection, ok := projections.Load(t); ok {
+		return projection.(bson.M)
+	}
+
+	projection := bson.M{}
+	if !addProjectedFields(projection, t) || len(projection) == 0 {
+		projection = nil
+	} else if _, ok := projection["_id"]; !ok {
+		projection["_id"] = 0
+	}
+
+	projections.Store(t, projection)
+	return projection
+}
+
+// addProjectedFields - Add the fields of a struct type and its inline structs
+// to a projection, named as the driver names them: by bson tag, else by the
+// lowercased field name. Returns false if a map is inlined, it takes every
+// other field of the document.
+func addProjectedFields(projection bson.M, t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// the driver only reads unexported fields of embedded structs
+		if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		name, opts := parseTag(bsonTag(field))
+		if name == "-" {
+			continue
+		}
+
+		if hasTagOpt(opts, "inline") {
+			inner := field.Type
+			if inner.Kind() == reflect.Ptr {
+				inner = inner.Elem()
+			}
+			if inner.Kind() == reflect.Map {
+				return false
+			}
+			if inner.Kind() == reflect.Struct && !addProjectedFields(projection, inner) {
+				return false
+			}
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		projection[name] = 1
+	}
+	return true
+}
+
+// bsonTag - Get the bson tag of a field as the driver reads it: a tag without
+// a key, such as `name,omitempty`, is used as the bson tag
+func bsonTag(field reflect.StructField) string {
+	tag, ok := field.Tag.Lookup("bson")
+	if !ok && !strings.Contains(string(field.Tag), ":") {
+		tag = string(field.Tag)
+	}
+	return tag
+}
+
+// projectedFindOptions - Prepend the projection of V to opts, so opts can override it
+func projectedFindOptions[V any](opts []*options.FindOptions) []*options.FindOptions {
+	projection := projectionOf(reflect.TypeOf((*V)(nil)).Elem())
+	if projection == nil {
+		return opts
+	}
+	return append([]*options.FindOptions{options.Find().SetProjection(projection)}, opts...)
+}
+
+// FindProjected - Find documents decoded into a view struct, fetching only the
+// fields of V. Untagged fields are fetched by their lowercased name, as the
+// driver decodes them.
+//
+//	type UserCard struct {
+//		Name   string `bson:"name"`
+//		Avatar string `bson:"avatar"`
+//	}
+//
+//	cards, err := gmongo.FindProjected[UserCard](UserModel, bson.M{"verified": true})
+func FindProjected[V any, T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], filter interface{}, opts ...*options.FindOptions) ([]V, error) {
+	results := make([]V, 0)
+	err := coll.FindAs(&results, filter, projectedFindOptions[V](opts)...)
+	return results, err
+}
+
+// FindOneProjected - Find one document decoded into a view struct, see FindProjected
+func FindOneProjected[V any, T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], filter interface{}, opts ...*options.FindOneOptions) (V, error) {
+	var result V
+
+	if projection := projectionOf(reflect.TypeOf((*V)(nil)).Elem()); projection != nil {
+		opts = append([]*options.FindOneOptions{options.FindOne().SetProjection(projection)}, opts...)
+	}
+
+	err := coll.FindOneAs(&result, filter, opts...)
+	return result, err
+}
+
+// PaginateProjected - Paginate Find decoded into a view struct, see FindProjected and PaginateWith
+func PaginateProjected[V any, T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], req PaginationRequest, filter interface{}, opts ...*options.FindOptions) (*Paginated[[]V], error) {
+	req, query, totalCount, err := coll.countFind(req, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]V, 0)
+	if totalCount > 0 {
+		if err = coll.findPage(req, query, &data, projectedFindOptions[V](opts)...); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Paginated[[]V]{Meta: newPaginatedMeta(totalCount, req.Page, req.PerPage), Data: data}, nil
+}
@@ -0,0 +1,101 @@
+package gmongo
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UserCard struct {
+	Name     string `bson:"name"`
+	Verified bool   `bson:"verified"`
+}
+
+type UserRef struct {
+	ID   primitive.ObjectID `bson:"_id"`
+	Name string             `bson:"name"`
+}
+
+func Test_projectionOf(t *testing.T) {
+	assert.Equal(t, bson.M{"_id": 0, "name": 1, "verified": 1}, projectionOf(reflect.TypeOf(UserCard{})))
+	assert.Equal(t, bson.M{"_id": 1, "name": 1}, projectionOf(reflect.TypeOf(&UserRef{})))
+
+	// inline fields are flattened, nested structs are projected whole
+	assert.Equal(t, bson.M{"_id": 1, "createdAt": 1, "name": 1, "age": 1}, projectionOf(reflect.TypeOf(StampedUser{})))
+	assert.Equal(t, bson.M{"_id": 0, "avatar": 1, "bio": 1, "secret": 1}, projectionOf(reflect.TypeOf(Profile{})))
+
+	// untagged fields use the driver's lowercased name
+	assert.Equal(t, bson.M{"_id": 0, "name": 1, "fullname": 1}, projectionOf(reflect.TypeOf(struct {
+		Name     string
+		FullName string `bson:",omitempty"`
+		Hidden   string `bson:"-"`
+		secret   string
+	}{})))
+
+	// inline maps take every other field, nothing is projected
+	assert.Nil(t, projectionOf(reflect.TypeOf(struct {
+		Name  string                 `bson:"name"`
+		Extra map[string]interface{} `bson:",inline"`
+	}{})))
+
+	assert.Nil(t, projectionOf(reflect.TypeOf(struct{ name string }{})))
+	assert.Nil(t, projectionOf(reflect.TypeOf("")))
+}
+
+func TestFindProjected(t *testing.T) {
+	client := testConnectToDb()
+
+	UserModel := MakeModel[*User](client.Database, "projected_users")
+	_, _ = UserModel.DeleteMany(bson.M{})
+
+	users := []*User{
+		{ID: primitive.NewObjectID(), Name: "John", Age: 20, Verified: true},
+		{ID: primitive.NewObjectID(), Name: "Jane", Age: 30, Verified: true},
+		{ID: primitive.NewObjectID(), Name: "Jack", Age: 40},
+	}
+	_, err := UserModel.InsertMany(users)
+	assert.Nil(t, err)
+
+	t.Run("FindProjected", func(t *testing.T) {
+		cards, err := FindProjected[UserCard](&UserModel, bson.M{"verified": true})
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []UserCard{{Name: "John", Verified: true}, {Name: "Jane", Verified: true}}, cards)
+
+		refs, err := FindProjected[*UserRef](&UserModel, bson.M{"name": "Jack"})
+		assert.Nil(t, err)
+		assert.Equal(t, []*UserRef{{ID: users[2].ID, Name: "Jack"}}, refs)
+
+		// only the projected fields are fetched
+		var raw []bson.M
+		err = UserModel.FindAs(&raw, bson.M{"name": "Jack"}, projectedFindOptions[UserCard](nil)...)
+		assert.Nil(t, err)
+		assert.Equal(t, []bson.M{{"name": "Jack", "verified": false}}, raw)
+	})
+
+	t.Run("FindOneProjected", func(t *testing.T) {
+		card, err := FindOneProjected[UserCard](&UserModel, bson.M{"name": "John"})
+		assert.Nil(t, err)
+		assert.Equal(t, UserCard{Name: "John", Verified: true}, card)
+
+		_, err = FindOneProjected[UserCard](&UserModel, bson.M{"name": "Nobody"})
+		assert.True(t, IsNoDocumentsError(err))
+	})
+
+	t.Run("PaginateProjected", func(t *testing.T) {
+		page, err := PaginateProjected[UserCard](&UserModel, PaginationRequest{
+			Page:    1,
+			PerPage: 2,
+			Sort:    bson.D{{Key: "age", Value: 1}},
+		}, bson.M{})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, page.Meta.Total)
+		assert.Equal(t, []UserCard{{Name: "John", Verified: true}, {Name: "Jane", Verified: true}}, page.Data)
+
+		page, err = PaginateProjected[UserCard](&UserModel, PaginationRequest{Page: 1}, bson.M{"name": "Nobody"})
+		assert.Nil(t, err)
+		assert.Equal(t, []UserCard{}, page.Data)
+	})
+}
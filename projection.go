@@ -1,7 +1,6 @@
 package gmongo
 
 import (
-	"github.com/samber/lo"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -57,14 +56,15 @@ var Projection = struct {
 	OmitIdAndPick: omitIdAndPick,
 }
 
-func (coll *Model[T]) ToBsonMap(data ModelData) bson.M {
+func (coll *ModelOf[T, ID]) ToBsonMap(data interface{}) bson.M {
 	return structToMapWithTags(data, "bson")
 }
 
-func (coll *Model[T]) ToJsonMap(data ModelData) bson.M {
+func (coll *ModelOf[T, ID]) ToJsonMap(data interface{}) bson.M {
 	return structToMapWithTags(data, "json")
 }
 
-func (coll *Model[T]) Pick(data ModelData, keys []string) bson.M {
-	return lo.PickByKeys(coll.ToBsonMap(data), keys)
+// Pick - Pick bson paths from data, nested fields are picked with dotted paths such as "profile.avatar"
+func (coll *ModelOf[T, ID]) Pick(data interface{}, keys []string) bson.M {
+	return pickPaths(data, keys, "bson")
 }
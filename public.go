@@ -0,0 +1,183 @@
+package gmongo
+
+import (
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// pathTree - Dotted paths as a tree, a nil subtree selects the whole value
+type pathTree map[string]pathTree
+
+// newPathTree - Build the tree of dotted paths such as "profile.avatar"
+func newPathTree(paths []string) pathTree {
+	tree := pathTree{}
+	for _, path := range paths {
+		node := tree
+		parts := strings.Split(path, ".")
+		for i, part := range parts {
+			child, exists := node[part]
+			if i == len(parts)-1 {
+				node[part] = nil
+				break
+			}
+			if exists && child == nil {
+				// the whole value is already selected
+				break
+			}
+			if !exists {
+				child = pathTree{}
+				node[part] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// keyName - Get the output key of a field of a bson plan. With the json tag,
+// fields are named like encoding/json names them.
+func keyName(t reflect.Type, field fieldPlan, keyTag string) string {
+	if keyTag == "bson" {
+		return field.name
+	}
+
+	plan := planOf(t, keyTag)
+	if i, ok := plan.byIndex[field.index]; ok {
+		return plan.fields[i].name
+	}
+	if plan.hidden[field.index] {
+		return ""
+	}
+	return t.Field(field.index).Name
+}
+
+// pickValue - Pick the paths of tree from a value, a nil tree picks it whole.
+// Structs become maps keyed by keyTag names, slices and maps are picked item
+// by item.
+func pickValue(v reflect.Value, tree pathTree, keyTag string) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if isLeafStruct(v.Type()) {
+			return v.Interface()
+		}
+		return pickStruct(v, tree, keyTag)
+	case reflect.Slice, reflect.Array:
+		if !hasStructs(v.Type().Elem()) {
+			return v.Interface()
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = pickValue(v.Index(i), tree, keyTag)
+		}
+		return items
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || (tree == nil && !hasStructs(v.Type().Elem())) {
+			return v.Interface()
+		}
+		if v.IsNil() {
+			return nil
+		}
+		res := map[string]interface{}{}
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			sub, ok := tree[key]
+			if tree != nil && !ok {
+				continue
+			}
+			res[key] = pickValue(iter.Value(), sub, keyTag)
+		}
+		return res
+	}
+
+	return v.Interface()
+}
+
+// pickStruct - Pick the paths of tree from a struct, a nil tree picks all fields
+func pickStruct(v reflect.Value, tree pathTree, keyTag string) map[string]interface{} {
+	res := map[string]interface{}{}
+	t := v.Type()
+
+	for _, field := range planOf(t, "bson").fields {
+		fieldVal := v.Field(field.index)
+
+		if field.inline {
+			if fieldVal.Kind() == reflect.Ptr {
+				if fieldVal.IsNil() {
+					continue
+				}
+				fieldVal = fieldVal.Elem()
+			}
+			for key, value := range pickStruct(fieldVal, tree, keyTag) {
+				res[key] = value
+			}
+			continue
+		}
+
+		sub, ok := tree[field.name]
+		if tree != nil && !ok {
+			continue
+		}
+		if sub != nil && !field.hasStructs {
+			// a path into a value without fields, e.g. "name.first" of a string
+			continue
+		}
+
+		key := keyName(t, field, keyTag)
+		if key == "" {
+			continue
+		}
+		res[key] = pickValue(fieldVal, sub, keyTag)
+	}
+
+	return res
+}
+
+// hasStructs - Check if values of a type may contain fields to pick
+func hasStructs(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Interface || (t.Kind() == reflect.Struct && !isLeafStruct(t))
+}
+
+// pickPaths - Pick dotted bson paths from a struct, keyed by the names of keyTag ("bson" or "json")
+func pickPaths(data interface{}, paths []string, keyTag string) bson.M {
+	return pickTree(data, newPathTree(paths), keyTag)
+}
+
+// pickTree - Pick the paths of tree from a struct, a nil tree picks all fields
+func pickTree(data interface{}, tree pathTree, keyTag string) bson.M {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return bson.M{}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return bson.M{}
+	}
+
+	return pickStruct(v, tree, keyTag)
+}
+
+// publicKeyTag - Get the tag naming the keys of public fields
+func (coll *ModelOf[T, ID]) publicKeyTag() string {
+	if coll.PublicFieldsTag == "" {
+		return "bson"
+	}
+	return coll.PublicFieldsTag
+}
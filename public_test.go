@@ -0,0 +1,124 @@
+package gmongo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Avatar struct {
+	URL  string `bson:"url" json:"url"`
+	Size int    `bson:"size" json:"size"`
+}
+
+type Profile struct {
+	Avatar *Avatar `bson:"avatar" json:"avatar"`
+	Bio    string  `bson:"bio" json:"bio"`
+	Secret string  `bson:"secret" json:"-"`
+}
+
+type Order struct {
+	Number string  `bson:"number" json:"number"`
+	Total  float64 `bson:"total" json:"total"`
+}
+
+type Member struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	Name      string             `bson:"name" json:"displayName"`
+	Email     string             `bson:"email"`
+	Profile   Profile            `bson:"profile" json:"profile"`
+	Orders    []Order            `bson:"orders" json:"orders"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+func (m *Member) GetID() primitive.ObjectID { return m.ID }
+
+func Test_newPathTree(t *testing.T) {
+	assert.Equal(t, pathTree{
+		"name":    nil,
+		"profile": pathTree{"avatar": pathTree{"url": nil}, "bio": nil},
+		"orders":  nil,
+	}, newPathTree([]string{"name", "profile.avatar.url", "profile.bio", "orders.number", "orders"}))
+}
+
+func Test_planOf(t *testing.T) {
+	plan := planOf(reflect.TypeOf(Profile{}), "json")
+	assert.Same(t, plan, planOf(reflect.TypeOf(Profile{}), "json"))
+	assert.Len(t, plan.fields, 2)
+	assert.True(t, plan.hidden[2])
+}
+
+func TestModel_GetPublicFields_Paths(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	member := &Member{
+		ID:    primitive.NewObjectID(),
+		Name:  "John",
+		Email: "john@example.com",
+		Profile: Profile{
+			Avatar: &Avatar{URL: "https://example.com/a.png", Size: 64},
+			Bio:    "Hello",
+			Secret: "s3cret",
+		},
+		Orders:    []Order{{Number: "1", Total: 10}, {Number: "2", Total: 20}},
+		CreatedAt: createdAt,
+	}
+
+	MemberModel := CreateModel[*Member]("members")
+	MemberModel.PublicFields = []string{"name", "profile.avatar.url", "profile.secret", "orders.number", "createdAt", "email.domain"}
+
+	t.Run("Nested paths, slices and leaf structs", func(t *testing.T) {
+		assert.Equal(t, bson.M{
+			"name": "John",
+			"profile": map[string]interface{}{
+				"avatar": map[string]interface{}{"url": "https://example.com/a.png"},
+				"secret": "s3cret",
+			},
+			"orders": []interface{}{
+				map[string]interface{}{"number": "1"},
+				map[string]interface{}{"number": "2"},
+			},
+			"createdAt": createdAt,
+		}, MemberModel.GetPublicFields(member))
+	})
+
+	t.Run("JSON keys", func(t *testing.T) {
+		jsonModel := *MemberModel
+		jsonModel.PublicFieldsTag = "json"
+		jsonModel.PublicFields = []string{"name", "email", "profile", "orders.total"}
+
+		assert.Equal(t, bson.M{
+			"displayName": "John",
+			// no json tag, named like encoding/json
+			"Email": "john@example.com",
+			"profile": map[string]interface{}{
+				"avatar": map[string]interface{}{"url": "https://example.com/a.png", "size": 64},
+				"bio":    "Hello",
+			},
+			"orders": []interface{}{
+				map[string]interface{}{"total": float64(10)},
+				map[string]interface{}{"total": float64(20)},
+			},
+		}, jsonModel.GetPublicFields(member))
+	})
+
+	t.Run("Nil pointers and slices", func(t *testing.T) {
+		empty := &Member{Name: "Jane"}
+		assert.Equal(t, bson.M{
+			"name":      "Jane",
+			"profile":   map[string]interface{}{"avatar": nil, "secret": ""},
+			"orders":    nil,
+			"createdAt": time.Time{},
+		}, MemberModel.GetPublicFields(empty))
+
+		assert.Equal(t, bson.M{}, MemberModel.GetPublicFields((*Member)(nil)))
+	})
+
+	t.Run("Helper and Pick", func(t *testing.T) {
+		assert.Equal(t, MemberModel.GetPublicFields(member), MemberModel.Helpers(member).GetPublicFields())
+		assert.Equal(t, bson.M{"profile": map[string]interface{}{"bio": "Hello"}}, MemberModel.Pick(member, []string{"profile.bio"}))
+	})
+}
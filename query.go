@@ -0,0 +1,282 @@
+package gmongo
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// DefaultQueryOperators - Operators allowed by ParseQuery when QueryOptions.Operators is empty
+var DefaultQueryOperators = []string{"eq", "ne", "gt", "gte", "lt", "lte", "in", "nin", "exists"}
+
+// ErrInvalidQuery - Returned (wrapped in a *QueryError) for query strings that cannot be parsed
+var ErrInvalidQuery = errors.New("invalid query")
+
+// QueryError - A query string parameter that is not allowed or cannot be parsed
+type QueryError struct {
+	Key    string
+	Reason string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("gmongo: %s: %s: %s", ErrInvalidQuery, e.Key, e.Reason)
+}
+
+func (e *QueryError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+// IsQueryError - Check if the error is a query string error
+func IsQueryError(err error) bool {
+	return errors.Is(err, ErrInvalidQuery)
+}
+
+// QueryOptions - Options for ParseQuery
+type QueryOptions struct {
+	// Fields - Fields that can be filtered on, defaults to the model PublicFields
+	Fields []string
+	// SortFields - Fields that can be sorted on, defaults to Fields
+	SortFields []string
+	// Operators - Operators that can be used, defaults to DefaultQueryOperators
+	Operators []string
+	// MaxPerPage - Upper bound of perPage, see PaginationRequest
+	MaxPerPage int
+}
+
+// ParsedQuery - Filter and pagination parsed from a query string
+type ParsedQuery struct {
+	Filter     bson.M
+	Pagination PaginationRequest
+}
+
+// queryKeyField - Get the field of a query key, "age" of "age[gte]"
+func queryKeyField(key string) string {
+	if open := strings.IndexByte(key, '['); open != -1 {
+		return key[:open]
+	}
+	return key
+}
+
+// parseQueryKey - Split "age[gte]" into "age" and "gte"
+func parseQueryKey(key string) (field string, op string, ok bool) {
+	open := strings.IndexByte(key, '[')
+	if open == -1 {
+		return key, "eq", true
+	}
+
+	if !strings.HasSuffix(key, "]") || open == 0 {
+		return "", "", false
+	}
+
+	return key[:open], key[open+1 : len(key)-1], true
+}
+
+// parseQueryValue - Convert a query string value to a bool, an int64, a float64 or keep it as a string.
+// Numbers are only converted when the value is in canonical form, so "0801" stays a string.
+func parseQueryValue(value string) interface{} {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil && strconv.FormatInt(i, 10) == value {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil && strconv.FormatFloat(f, 'f', -1, 64) == value {
+		return f
+	}
+
+	return value
+}
+
+// parseQueryInt - Parse a pagination parameter, missing values are 0
+func parseQueryInt(values url.Values, key string) (int, error) {
+	value := values.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &QueryError{Key: key, Reason: "must be an integer"}
+	}
+
+	return i, nil
+}
+
+// parseQuerySort - Parse "-createdAt,name" into a sort document
+func parseQuerySort(value string, allowed []string) (bson.D, error) {
+	sortDoc := bson.D{}
+
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		direction := 1
+		if strings.HasPrefix(field, "-") {
+			direction = -1
+			field = field[1:]
+		} else if strings.HasPrefix(field, "+") {
+			field = field[1:]
+		}
+
+		if !lo.Contains(allowed, field) {
+			return nil, &QueryError{Key: "sort", Reason: fmt.Sprintf("cannot sort by %q", field)}
+		}
+
+		sortDoc = append(sortDoc, bson.E{Key: field, Value: direction})
+	}
+
+	return sortDoc, nil
+}
+
+// ParseQuery - Convert a query string into a filter and a pagination request.
+//
+// Only fields in opt.Fields and operators in opt.Operators are accepted; other
+// parameters are ignored, while a disallowed operator or sort field, a malformed
+// key or a repeated single-valued key of an allowed field returns a *QueryError.
+// Repeated "in" and "nin" keys are combined.
+// Operators are never taken from values, so "?name[$ne]=" or "?name=$gt" cannot inject one.
+//
+// Example:
+//
+//	// ?page=2&perPage=20&sort=-createdAt&age[gte]=18&status[in]=active,pending
+//	q, err := gmongo.ParseQuery(r.URL.Query(), gmongo.QueryOptions{Fields: []string{"age", "status", "createdAt"}})
+//	// q.Filter will be {age: {$gte: 18}, status: {$in: ["active", "pending"]}}
+//	// q.Pagination will be {Page: 2, PerPage: 20, Sort: {createdAt: -1}}
+//	res, err := UserModel.PaginateWith(q.Pagination, q.Filter)
+func ParseQuery(values url.Values, opt QueryOptions) (*ParsedQuery, error) {
+	operators := opt.Operators
+	if len(operators) == 0 {
+		operators = DefaultQueryOperators
+	}
+
+	sortFields := opt.SortFields
+	if len(sortFields) == 0 {
+		sortFields = opt.Fields
+	}
+
+	page, err := parseQueryInt(values, "page")
+	if err != nil {
+		return nil, err
+	}
+
+	perPage, err := parseQueryInt(values, "perPage")
+	if err != nil {
+		return nil, err
+	}
+
+	query := &ParsedQuery{
+		Filter: bson.M{},
+		Pagination: PaginationRequest{
+			Page:       page,
+			PerPage:    perPage,
+			MaxPerPage: opt.MaxPerPage,
+		},
+	}
+
+	if value := values.Get("sort"); value != "" {
+		sortDoc, err := parseQuerySort(value, sortFields)
+		if err != nil {
+			return nil, err
+		}
+		query.Pagination.Sort = sortDoc
+	}
+
+	// sort keys so errors are reported consistently
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key == "page" || key == "perPage" || key == "sort" {
+			continue
+		}
+
+		if !lo.Contains(opt.Fields, queryKeyField(key)) {
+			continue
+		}
+
+		field, op, ok := parseQueryKey(key)
+		if !ok {
+			return nil, &QueryError{Key: key, Reason: "malformed key"}
+		}
+
+		if !lo.Contains(operators, op) {
+			return nil, &QueryError{Key: key, Reason: fmt.Sprintf("operator %q is not allowed", op)}
+		}
+
+		if op == "in" || op == "nin" {
+			// repeated keys add to the list, "status[in]=a,b&status[in]=c"
+			list := bson.A{}
+			for _, value := range values[key] {
+				for _, v := range strings.Split(value, ",") {
+					list = append(list, parseQueryValue(v))
+				}
+			}
+			addQueryOperator(query.Filter, field, op, list)
+			continue
+		}
+
+		if len(values[key]) > 1 {
+			return nil, &QueryError{Key: key, Reason: "must have a single value"}
+		}
+		value := values.Get(key)
+
+		var parsed interface{}
+		switch op {
+		case "exists":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, &QueryError{Key: key, Reason: "must be a boolean"}
+			}
+			parsed = b
+		default:
+			parsed = parseQueryValue(value)
+		}
+
+		addQueryOperator(query.Filter, field, op, parsed)
+	}
+
+	return query, nil
+}
+
+// addQueryOperator - Add {$op: value} to the conditions of field in filter
+func addQueryOperator(filter bson.M, field string, op string, value interface{}) {
+	ops, ok := filter[field].(bson.M)
+	if !ok {
+		ops = bson.M{}
+		filter[field] = ops
+	}
+	ops["$"+op] = value
+}
+
+// ParseQuery - Convert a query string into a filter and a pagination request, see ParseQuery.
+// opt.Fields defaults to the model PublicFields.
+func (coll *ModelOf[T, ID]) ParseQuery(values url.Values, opt *QueryOptions) (*ParsedQuery, error) {
+	var o QueryOptions
+	if opt != nil {
+		o = *opt
+	}
+
+	if len(o.Fields) == 0 {
+		o.Fields = coll.PublicFields
+	}
+
+	return ParseQuery(values, o)
+}
@@ -0,0 +1,105 @@
+package gmongo
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_parseQueryValue(t *testing.T) {
+	assert.Equal(t, int64(18), parseQueryValue("18"))
+	assert.Equal(t, 1.5, parseQueryValue("1.5"))
+	assert.Equal(t, true, parseQueryValue("true"))
+	assert.Equal(t, nil, parseQueryValue("null"))
+	assert.Equal(t, "0801", parseQueryValue("0801"))
+	assert.Equal(t, "John", parseQueryValue("John"))
+}
+
+func Test_ParseQuery(t *testing.T) {
+	opt := QueryOptions{Fields: []string{"age", "name", "status", "createdAt"}}
+
+	t.Run("Filter, sort and pagination", func(t *testing.T) {
+		values, _ := url.ParseQuery("page=2&perPage=20&sort=-createdAt,name&age[gte]=18&age[lt]=30&status[in]=active,pending&name=John&other=1")
+
+		q, err := ParseQuery(values, opt)
+		assert.NoError(t, err)
+
+		assert.Equal(t, bson.M{
+			"age":    bson.M{"$gte": int64(18), "$lt": int64(30)},
+			"status": bson.M{"$in": bson.A{"active", "pending"}},
+			"name":   bson.M{"$eq": "John"},
+		}, q.Filter)
+
+		assert.Equal(t, PaginationRequest{
+			Page:    2,
+			PerPage: 20,
+			Sort:    bson.D{{Key: "createdAt", Value: -1}, {Key: "name", Value: 1}},
+		}, q.Pagination)
+	})
+
+	t.Run("Rejects disallowed operators", func(t *testing.T) {
+		values, _ := url.ParseQuery("name[regex]=.*")
+		_, err := ParseQuery(values, opt)
+		assert.True(t, IsQueryError(err))
+
+		values, _ = url.ParseQuery("name[$where]=1")
+		_, err = ParseQuery(values, opt)
+		assert.True(t, IsQueryError(err))
+	})
+
+	t.Run("Ignores fields not allowed", func(t *testing.T) {
+		values, _ := url.ParseQuery("password[ne]=x&$where=1")
+		q, err := ParseQuery(values, opt)
+		assert.NoError(t, err)
+		assert.Equal(t, bson.M{}, q.Filter)
+	})
+
+	t.Run("Malformed keys of fields not allowed are ignored", func(t *testing.T) {
+		values, _ := url.ParseQuery("password[ne=x&[x]=1&utm[source=ad")
+		q, err := ParseQuery(values, opt)
+		assert.NoError(t, err)
+		assert.Equal(t, bson.M{}, q.Filter)
+
+		values, _ = url.ParseQuery("age[gte=18")
+		_, err = ParseQuery(values, opt)
+		assert.True(t, IsQueryError(err))
+	})
+
+	t.Run("Repeated values", func(t *testing.T) {
+		values, _ := url.ParseQuery("status[in]=active,pending&status[in]=closed&age[nin]=1&age[nin]=2")
+		q, err := ParseQuery(values, opt)
+		assert.NoError(t, err)
+		assert.Equal(t, bson.M{
+			"status": bson.M{"$in": bson.A{"active", "pending", "closed"}},
+			"age":    bson.M{"$nin": bson.A{int64(1), int64(2)}},
+		}, q.Filter)
+
+		values, _ = url.ParseQuery("name=John&name=Jane")
+		_, err = ParseQuery(values, opt)
+		assert.True(t, IsQueryError(err))
+	})
+
+	t.Run("Rejects disallowed sort fields", func(t *testing.T) {
+		values, _ := url.ParseQuery("sort=-password")
+		_, err := ParseQuery(values, opt)
+		assert.True(t, IsQueryError(err))
+	})
+
+	t.Run("Rejects invalid pagination", func(t *testing.T) {
+		values, _ := url.ParseQuery("page=two")
+		_, err := ParseQuery(values, opt)
+		assert.True(t, IsQueryError(err))
+	})
+
+	t.Run("Model defaults to public fields", func(t *testing.T) {
+		m := CreateModel[*User]("users")
+		m.PublicFields = []string{"name"}
+
+		values, _ := url.ParseQuery("name=John&age=20")
+		q, err := m.ParseQuery(values, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, bson.M{"name": bson.M{"$eq": "John"}}, q.Filter)
+	})
+}
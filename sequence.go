@@ -0,0 +1,230 @@
+package gmongo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultCountersCollection - Collection of sequence counters when Client.CountersCollection is empty
+const DefaultCountersCollection = "gmongo_counters"
+
+// SequenceOptions - Options of Client.Sequence
+type SequenceOptions struct {
+	// Start - The first value of the sequence, defaults to 1
+	Start int64
+	// BlockSize - Number of values reserved per round-trip, defaults to 1.
+	//
+	// With a block size of 1 the counter is incremented with the context of
+	// Next, so inside a transaction the value is rolled back with it and the
+	// sequence has no gaps. Larger blocks are reserved outside any transaction
+	// and kept in memory: values unused when the process stops are skipped,
+	// and values of concurrent processes interleave.
+	BlockSize int64
+	// Prefix - Prepended to formatted values, e.g. "INV-"
+	Prefix string
+	// Format - fmt verb formatting the value, defaults to "%d", e.g. "%06d"
+	Format string
+}
+
+// Sequence - A named counter stored in the counters collection, see Client.Sequence
+type Sequence struct {
+	Name   string
+	opt    SequenceOptions
+	client *Client
+
+	mu sync.Mutex
+	// next and last - The reserved block of values not handed out yet
+	next, last int64
+}
+
+// Sequence - Get the named sequence of the client.
+// The options are used when the sequence is first requested; later calls
+// return the same sequence, sharing its reserved block.
+//
+//	invoices := client.Sequence("invoices", gmongo.SequenceOptions{Prefix: "INV-", Format: "%06d"})
+//	number, err := invoices.NextString(ctx) // INV-000001
+func (c *Client) Sequence(name string, opts ...SequenceOptions) *Sequence {
+	if seq, ok := c.sequences.Load(name); ok {
+		return seq.(*Sequence)
+	}
+
+	opt := SequenceOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Start == 0 {
+		opt.Start = 1
+	}
+	if opt.BlockSize <= 0 {
+		opt.BlockSize = 1
+	}
+	if opt.Format == "" {
+		opt.Format = "%d"
+	}
+
+	seq, _ := c.sequences.LoadOrStore(name, &Sequence{Name: name, opt: opt, client: c})
+	return seq.(*Sequence)
+}
+
+// counters - Get the counters collection of the client
+func (c *Client) counters() *mongo.Collection {
+	name := c.CountersCollection
+	if name == "" {
+		name = DefaultCountersCollection
+	}
+	return c.Database.Collection(name)
+}
+
+// reserve - Increment the counter by size and return the last reserved value
+func (s *Sequence) reserve(ctx context.Context, size int64) (int64, error) {
+	var counter struct {
+		Value int64 `bson:"value"`
+	}
+
+	err := s.client.counters().FindOneAndUpdate(ctx,
+		bson.M{"_id": s.Name},
+		bson.M{"$inc": bson.M{"value": size}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+
+	return counter.Value + s.opt.Start - 1, nil
+}
+
+// Next - Get the next value of the sequence
+func (s *Sequence) Next(ctx context.Context) (int64, error) {
+	if s.opt.BlockSize == 1 {
+		return s.reserve(ctx, 1)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.next == 0 || s.next > s.last {
+		// reserve outside a transaction of ctx, a rolled back block would be handed out twice
+		rctx := context.Background()
+		if deadline, ok := ctx.Deadline(); ok {
+			var cancel context.CancelFunc
+			rctx, cancel = context.WithDeadline(rctx, deadline)
+			defer cancel()
+		}
+
+		last, err := s.reserve(rctx, s.opt.BlockSize)
+		if err != nil {
+			return 0, err
+		}
+		s.next, s.last = last-s.opt.BlockSize+1, last
+	}
+
+	value := s.next
+	s.next++
+	return value, nil
+}
+
+// Format - Format a value with the prefix and format of the sequence
+func (s *Sequence) Format(value int64) string {
+	return s.opt.Prefix + fmt.Sprintf(s.opt.Format, value)
+}
+
+// NextString - Get the next value of the sequence, formatted
+func (s *Sequence) NextString(ctx context.Context) (string, error) {
+	value, err := s.Next(ctx)
+	if err != nil {
+		return "", err
+	}
+	return s.Format(value), nil
+}
+
+// EnableSequences - Fill fields tagged `gmongo:"sequence=<name>"` from the
+// sequences of client on InsertOne and InsertMany. Only zero fields of
+// documents passed by pointer are filled; integer fields get the value and
+// string fields the formatted value.
+//
+// The sequences must be registered with client.Sequence before the first
+// insert, so their options apply; inserts fail on unregistered sequences.
+//
+//	client.Sequence("invoices", gmongo.SequenceOptions{Prefix: "INV-"})
+//	InvoiceModel.EnableSequences(client)
+//
+//	type Invoice struct {
+//		ID     primitive.ObjectID `bson:"_id"`
+//		Number string             `bson:"number" gmongo:"sequence=invoices"`
+//	}
+func (coll *ModelOf[T, ID]) EnableSequences(client *Client) {
+	coll.sequences = client
+}
+
+// fillSequences - Fill the sequence fields of a document
+func (c *Client) fillSequences(ctx context.Context, doc interface{}) error {
+	value := reflect.ValueOf(doc)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return nil
+	}
+
+	return c.fillSequenceFields(ctx, value.Elem())
+}
+
+// fillSequenceFields - Fill the sequence fields of a struct and its embedded structs
+func (c *Client) fillSequenceFields(ctx context.Context, value reflect.Value) error {
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field, fieldValue := value.Type().Field(i), value.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous {
+			if fieldValue.Kind() == reflect.Ptr {
+				if fieldValue.IsNil() {
+					continue
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			if err := c.fillSequenceFields(ctx, fieldValue); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, ok := tagOption(field.Tag.Get("gmongo"), "sequence")
+		if !ok || name == "" || !fieldValue.IsZero() {
+			continue
+		}
+
+		// not c.Sequence(name), which would register the sequence without its options
+		registered, ok := c.sequences.Load(name)
+		if !ok {
+			return fmt.Errorf("gmongo: sequence %s of field %s is not registered, call Client.Sequence first", name, field.Name)
+		}
+
+		seq := registered.(*Sequence)
+		next, err := seq.Next(ctx)
+		if err != nil {
+			return err
+		}
+
+		switch fieldValue.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fieldValue.SetInt(next)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fieldValue.SetUint(uint64(next))
+		case reflect.String:
+			fieldValue.SetString(seq.Format(next))
+		default:
+			return fmt.Errorf("gmongo: sequence field %s must be an integer or a string", field.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package gmongo
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NumberedInvoice struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	Number string             `bson:"number" gmongo:"sequence=test_invoice_numbers"`
+	Seq    int64              `bson:"seq" gmongo:"sequence=test_invoice_seq"`
+}
+
+func (i *NumberedInvoice) GetID() primitive.ObjectID { return i.ID }
+
+func TestSequence_Format(t *testing.T) {
+	client := &Client{}
+
+	assert.Equal(t, "7", client.Sequence("plain").Format(7))
+	assert.Equal(t, "INV-000042", client.Sequence("invoices", SequenceOptions{Prefix: "INV-", Format: "%06d"}).Format(42))
+
+	// options apply when the sequence is first requested
+	assert.Same(t, client.Sequence("invoices"), client.Sequence("invoices", SequenceOptions{Prefix: "X"}))
+	assert.Equal(t, "INV-000001", client.Sequence("invoices").Format(1))
+}
+
+func TestSequence_Unregistered(t *testing.T) {
+	client := &Client{}
+
+	err := client.fillSequences(context.TODO(), &NumberedInvoice{})
+	assert.NotNil(t, err)
+
+	// not registered with the default options as a side effect
+	_, ok := client.sequences.Load("test_invoice_numbers")
+	assert.False(t, ok)
+}
+
+func TestSequence(t *testing.T) {
+	client := testConnectToDb()
+	client.CountersCollection = "test_counters"
+
+	reset := func() {
+		_, _ = client.counters().DeleteMany(context.TODO(), bson.M{})
+		client.sequences = sync.Map{}
+	}
+
+	t.Run("Next", func(t *testing.T) {
+		reset()
+
+		seq := client.Sequence("test", SequenceOptions{Start: 1000})
+		for _, want := range []int64{1000, 1001, 1002} {
+			value, err := seq.Next(context.TODO())
+			assert.Nil(t, err)
+			assert.Equal(t, want, value)
+		}
+	})
+
+	t.Run("Blocks are unique across concurrent callers", func(t *testing.T) {
+		reset()
+
+		a := client.Sequence("test", SequenceOptions{BlockSize: 10})
+		// a second process sharing the counter
+		b := (&Client{Database: client.Database, CountersCollection: client.CountersCollection}).
+			Sequence("test", SequenceOptions{BlockSize: 10})
+
+		var mu sync.Mutex
+		seen := map[int64]bool{}
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(seq *Sequence) {
+				defer wg.Done()
+				value, err := seq.Next(context.TODO())
+				assert.Nil(t, err)
+
+				mu.Lock()
+				defer mu.Unlock()
+				assert.False(t, seen[value], "duplicate value %d", value)
+				seen[value] = true
+			}([]*Sequence{a, b}[i%2])
+		}
+		wg.Wait()
+
+		assert.Len(t, seen, 50)
+	})
+
+	t.Run("InsertOne fills tagged fields", func(t *testing.T) {
+		reset()
+
+		client.Sequence("test_invoice_numbers", SequenceOptions{Prefix: "INV-", Format: "%04d"})
+		client.Sequence("test_invoice_seq")
+		InvoiceModel := MakeModel[*NumberedInvoice](client.Database, "numbered_invoices")
+		InvoiceModel.EnableSequences(client)
+		_, _ = InvoiceModel.DeleteMany(bson.M{})
+
+		first := &NumberedInvoice{ID: primitive.NewObjectID()}
+		_, err := InvoiceModel.InsertOne(first)
+		assert.Nil(t, err)
+		assert.Equal(t, "INV-0001", first.Number)
+		assert.Equal(t, int64(1), first.Seq)
+
+		// set fields are kept
+		second := &NumberedInvoice{ID: primitive.NewObjectID(), Number: "MANUAL"}
+		third := &NumberedInvoice{ID: primitive.NewObjectID()}
+		_, err = InvoiceModel.InsertMany([]*NumberedInvoice{second, third})
+		assert.Nil(t, err)
+		assert.Equal(t, "MANUAL", second.Number)
+		assert.Equal(t, "INV-0002", third.Number)
+		assert.Equal(t, int64(3), third.Seq)
+
+		saved, err := InvoiceModel.FindOneById(third.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "INV-0002", saved.Number)
+	})
+}
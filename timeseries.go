@@ -0,0 +1,220 @@
+package gmongo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TimeUnit - Granularity of a time bucket, as accepted by $dateTrunc
+type TimeUnit string
+
+const (
+	TimeUnitHour  TimeUnit = "hour"
+	TimeUnitDay   TimeUnit = "day"
+	TimeUnitWeek  TimeUnit = "week"
+	TimeUnitMonth TimeUnit = "month"
+	TimeUnitYear  TimeUnit = "year"
+)
+
+// TimeSeriesOptions - Options for TimeSeries
+type TimeSeriesOptions struct {
+	// DateField - The date field to bucket by, e.g. "createdAt"
+	DateField string
+	// Unit - The bucket granularity
+	Unit TimeUnit
+	// Timezone - IANA timezone the buckets are computed in, defaults to UTC
+	Timezone string
+	// StartOfWeek - First day of a TimeUnitWeek bucket, defaults to sunday
+	StartOfWeek time.Weekday
+	// Keys - The keys to sum in each bucket
+	Keys []string
+	// Filter - Optional filter applied before bucketing
+	Filter interface{}
+	// From - Optional inclusive lower bound, the series starts at its bucket
+	From time.Time
+	// To - Optional exclusive upper bound, the series ends at its bucket
+	To time.Time
+}
+
+// TimeBucket - A single bucket of a time series
+type TimeBucket[V Number] struct {
+	Start  time.Time    `json:"start"`
+	Count  int64        `json:"count"`
+	Values map[string]V `json:"values"`
+}
+
+// truncateTime - Truncate t to the start of its bucket in loc, the same way $dateTrunc does
+func truncateTime(t time.Time, unit TimeUnit, loc *time.Location, startOfWeek time.Weekday) time.Time {
+	t = t.In(loc)
+	switch unit {
+	case TimeUnitHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	case TimeUnitWeek:
+		diff := (int(t.Weekday()) - int(startOfWeek) + 7) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-diff, 0, 0, 0, 0, loc)
+	case TimeUnitMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	case TimeUnitYear:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+// nextBucket - Get the start of the bucket after start
+func nextBucket(start time.Time, unit TimeUnit) time.Time {
+	switch unit {
+	case TimeUnitHour:
+		return start.Add(time.Hour)
+	case TimeUnitWeek:
+		return start.AddDate(0, 0, 7)
+	case TimeUnitMonth:
+		return start.AddDate(0, 1, 0)
+	case TimeUnitYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// fillTimeBuckets - Build an ordered series from first to last, filling missing buckets with zero values
+func fillTimeBuckets[V Number](buckets map[int64]TimeBucket[V], first, last time.Time, unit TimeUnit, keys []string) []TimeBucket[V] {
+	series := make([]TimeBucket[V], 0, len(buckets))
+
+	for start := first; !start.After(last); start = nextBucket(start, unit) {
+		if bucket, ok := buckets[start.UnixMilli()]; ok {
+			bucket.Start = start
+			series = append(series, bucket)
+			continue
+		}
+
+		bucket := TimeBucket[V]{Start: start, Values: make(map[string]V, len(keys))}
+		for _, key := range keys {
+			bucket.Values[key] = V(0)
+		}
+		series = append(series, bucket)
+	}
+
+	return series
+}
+
+// TimeSeries - Sum keys per time bucket (hour, day, week, month or year).
+//
+// Buckets are computed with $dateTrunc (MongoDB 5.0+) and returned in order,
+// buckets without documents are included with zero values.
+//
+// Example:
+//
+//	series, _ := gmongo.TimeSeries[int](TransactionModel, gmongo.TimeSeriesOptions{
+//		DateField: "createdAt",
+//		Unit:      gmongo.TimeUnitDay,
+//		Timezone:  "Africa/Lagos",
+//		Keys:      []string{"credit", "debit"},
+//	})
+//	// series will be [{Start: 2024-01-01, Count: 2, Values: {credit: 300, debit: 700}}, ...]
+func TimeSeries[V Number, T ModelDataOf[ID], ID any](coll *ModelOf[T, ID], opt TimeSeriesOptions) ([]TimeBucket[V], error) {
+	if opt.DateField == "" {
+		return nil, errors.New("gmongo: TimeSeries requires a DateField")
+	}
+
+	if opt.Unit == "" {
+		opt.Unit = TimeUnitDay
+	}
+
+	switch opt.Unit {
+	case TimeUnitHour, TimeUnitDay, TimeUnitWeek, TimeUnitMonth, TimeUnitYear:
+	default:
+		return nil, fmt.Errorf("gmongo: unsupported time unit %q", opt.Unit)
+	}
+
+	if opt.Timezone == "" {
+		opt.Timezone = "UTC"
+	}
+
+	loc, err := time.LoadLocation(opt.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	// restrict to the requested range
+	match := bson.A{}
+	if opt.Filter != nil {
+		match = append(match, opt.Filter)
+	}
+	if !opt.From.IsZero() {
+		match = append(match, bson.M{opt.DateField: bson.M{"$gte": opt.From}})
+	}
+	if !opt.To.IsZero() {
+		match = append(match, bson.M{opt.DateField: bson.M{"$lt": opt.To}})
+	}
+
+	pipeline := bson.A{}
+	if len(match) > 0 {
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"$and": match}})
+	}
+
+	dateTrunc := bson.M{
+		"date":     fmt.Sprintf("$%s", opt.DateField),
+		"unit":     string(opt.Unit),
+		"timezone": opt.Timezone,
+	}
+	if opt.Unit == TimeUnitWeek {
+		dateTrunc["startOfWeek"] = strings.ToLower(opt.StartOfWeek.String())
+	}
+
+	group := bson.M{
+		"_id":   bson.M{"$dateTrunc": dateTrunc},
+		"count": bson.M{"$sum": 1},
+	}
+	for i, key := range opt.Keys {
+		group[fmt.Sprintf("k%d", i)] = bson.M{"$sum": fmt.Sprintf("$%s", key)}
+	}
+
+	pipeline = append(pipeline, bson.M{"$group": group}, bson.M{"$sort": bson.M{"_id": 1}})
+
+	res, err := coll.Aggregate(pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make(map[int64]TimeBucket[V], len(res))
+	var first, last time.Time
+
+	for _, data := range res {
+		date, ok := data["_id"].(primitive.DateTime)
+		if !ok {
+			// documents without a valid date are grouped under null
+			continue
+		}
+
+		bucket := TimeBucket[V]{Start: date.Time().In(loc), Values: make(map[string]V, len(opt.Keys))}
+		bucket.Count, _ = toNumber[int64](data["count"])
+		for i, key := range opt.Keys {
+			bucket.Values[key], _ = toNumber[V](data[fmt.Sprintf("k%d", i)])
+		}
+
+		buckets[bucket.Start.UnixMilli()] = bucket
+		if first.IsZero() {
+			first = bucket.Start
+		}
+		last = bucket.Start
+	}
+
+	if !opt.From.IsZero() {
+		first = truncateTime(opt.From, opt.Unit, loc, opt.StartOfWeek)
+	}
+	if !opt.To.IsZero() {
+		last = truncateTime(opt.To.Add(-time.Millisecond), opt.Unit, loc, opt.StartOfWeek)
+	}
+
+	if first.IsZero() || last.IsZero() {
+		return []TimeBucket[V]{}, nil
+	}
+
+	return fillTimeBuckets(buckets, first, last, opt.Unit, opt.Keys), nil
+}
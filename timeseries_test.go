@@ -0,0 +1,39 @@
+package gmongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_truncateTime(t *testing.T) {
+	lagos, _ := time.LoadLocation("Africa/Lagos")
+	// 2024-03-13 (wednesday) 23:30 UTC is 2024-03-14 00:30 in Lagos
+	date := time.Date(2024, 3, 13, 23, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 3, 13, 23, 0, 0, 0, time.UTC), truncateTime(date, TimeUnitHour, time.UTC, time.Sunday))
+	assert.Equal(t, time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC), truncateTime(date, TimeUnitDay, time.UTC, time.Sunday))
+	assert.Equal(t, time.Date(2024, 3, 14, 0, 0, 0, 0, lagos), truncateTime(date, TimeUnitDay, lagos, time.Sunday))
+	assert.Equal(t, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), truncateTime(date, TimeUnitWeek, time.UTC, time.Sunday))
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), truncateTime(date, TimeUnitWeek, time.UTC, time.Monday))
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), truncateTime(date, TimeUnitMonth, time.UTC, time.Sunday))
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), truncateTime(date, TimeUnitYear, time.UTC, time.Sunday))
+}
+
+func Test_fillTimeBuckets(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	buckets := map[int64]TimeBucket[int]{
+		jan.UnixMilli(): {Start: jan, Count: 2, Values: map[string]int{"credit": 300}},
+		mar.UnixMilli(): {Start: mar, Count: 1, Values: map[string]int{"credit": 100}},
+	}
+
+	series := fillTimeBuckets(buckets, jan, mar, TimeUnitMonth, []string{"credit"})
+	assert.Equal(t, []TimeBucket[int]{
+		{Start: jan, Count: 2, Values: map[string]int{"credit": 300}},
+		{Start: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Count: 0, Values: map[string]int{"credit": 0}},
+		{Start: mar, Count: 1, Values: map[string]int{"credit": 100}},
+	}, series)
+}
@@ -0,0 +1,467 @@
+package gmongo
+
+import (
+	"errors"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// tracked - Check if writes of the model are audited or versioned
+func (coll *ModelOf[T, ID]) tracked() bool {
+	return coll.audit != nil || coll.history != nil
+}
+
+// toDocument - Convert a document to a bson.M with registry
+func toDocument(registry *bsoncodec.Registry, doc interface{}) (bson.M, error) {
+	raw, err := marshalWith(registry, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var m bson.M
+	err = unmarshalWith(registry, raw, &m)
+	return m, err
+}
+
+// toOrderedDocument - Convert a document to a bson.D with registry, keeping the order of its fields
+func toOrderedDocument(registry *bsoncodec.Registry, doc interface{}) (bson.D, error) {
+	raw, err := marshalWith(registry, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var d bson.D
+	err = unmarshalWith(registry, raw, &d)
+	return d, err
+}
+
+// findDocument - Find one document as a bson.M, nil if none matches
+func (coll *ModelOf[T, ID]) findDocument(filter interface{}, opts ...*options.FindOneOptions) (bson.M, error) {
+	var doc bson.M
+	err := coll.Native().FindOne(coll.ctx(), filter, opts...).Decode(&doc)
+	if IsNoDocumentsError(err) {
+		return nil, nil
+	}
+	return doc, err
+}
+
+// trackedAttempts - How many times a tracked write is tried when the document
+// that was read no longer matches the filter by the time it is written
+const trackedAttempts = 3
+
+// readOptions - Options of the read before a tracked write, so it finds the
+// document the write would with the same collation and index
+func readOptions(collation *options.Collation, hint interface{}) *options.FindOneOptions {
+	opt := options.FindOne()
+	if collation != nil {
+		opt.SetCollation(collation)
+	}
+	if hint != nil {
+		opt.SetHint(hint)
+	}
+	return opt
+}
+
+// readTarget - Filter of the document that was read, still matching filter
+func readTarget(filter interface{}, before bson.M) bson.M {
+	if filter == nil {
+		filter = bson.M{}
+	}
+	return bson.M{"$and": bson.A{filter, bson.M{"_id": before["_id"]}}}
+}
+
+// recordChange - Snapshot the previous version and write the audit entry of a write
+func (coll *ModelOf[T, ID]) recordChange(op AuditOperation, id, filter interface{}, before, after bson.M) error {
+	if coll.history != nil && before != nil {
+		if err := coll.writeSnapshot(op, id, before); err != nil {
+			return err
+		}
+	} else if coll.history != nil && op == AuditInsert {
+		if err := coll.writeCreated(id); err != nil {
+			return err
+		}
+	}
+
+	if coll.audit != nil {
+		return coll.writeAuditEntry(op, id, filter, before, after)
+	}
+
+	return nil
+}
+
+// trackedInsertOne - InsertOne of a tracked model
+func (coll *ModelOf[T, ID]) trackedInsertOne(doc interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	res, err := coll.Native().InsertOne(coll.ctx(), doc, opts...)
+	if err != nil {
+		return res, err
+	}
+
+	after, err := toDocument(coll.registry(), doc)
+	if err != nil {
+		return res, err
+	}
+	after["_id"] = res.InsertedID
+
+	return res, coll.recordChange(AuditInsert, res.InsertedID, nil, nil, after)
+}
+
+// trackedUpdateOne - UpdateOne of a tracked model
+func (coll *ModelOf[T, ID]) trackedUpdateOne(filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	opt := options.MergeUpdateOptions(opts...)
+	return coll.trackedUpdate(filter, readOptions(opt.Collation, opt.Hint), func(target interface{}, upsert bool) (*mongo.UpdateResult, error) {
+		if !upsert {
+			return coll.Native().UpdateOne(coll.ctx(), target, update, opt, options.Update().SetUpsert(false))
+		}
+		return coll.Native().UpdateOne(coll.ctx(), target, update, opts...)
+	})
+}
+
+// trackedReplaceOne - ReplaceOne of a tracked model
+func (coll *ModelOf[T, ID]) trackedReplaceOne(filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	opt := options.MergeReplaceOptions(opts...)
+	return coll.trackedUpdate(filter, readOptions(opt.Collation, opt.Hint), func(target interface{}, upsert bool) (*mongo.UpdateResult, error) {
+		if !upsert {
+			return coll.Native().ReplaceOne(coll.ctx(), target, replacement, opt, options.Replace().SetUpsert(false))
+		}
+		return coll.Native().ReplaceOne(coll.ctx(), target, replacement, opts...)
+	})
+}
+
+// trackedUpdate - Run an update or replace of a single document, recording
+// the document before and after it.
+//
+// The document that was read is written with the caller's filter and its _id,
+// so conditions such as {version: n} still apply. If it no longer matches by
+// then, it is read again, and the write reports no match once it is out of attempts.
+func (coll *ModelOf[T, ID]) trackedUpdate(filter interface{}, readOpt *options.FindOneOptions, write func(target interface{}, upsert bool) (*mongo.UpdateResult, error)) (*mongo.UpdateResult, error) {
+	var before bson.M
+	var res *mongo.UpdateResult
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		before, err = coll.findDocument(filter, readOpt)
+		if err != nil {
+			return nil, err
+		}
+
+		if before == nil {
+			// nothing to record unless it upserts
+			res, err = write(filter, true)
+			break
+		}
+
+		// upserting the document that was read would duplicate its _id
+		res, err = write(readTarget(filter, before), false)
+		if err != nil || res.MatchedCount > 0 || attempt == trackedAttempts {
+			break
+		}
+	}
+	if err != nil {
+		return res, err
+	}
+
+	op, id := AuditUpdate, res.UpsertedID
+	if id != nil {
+		op = AuditInsert
+	} else if res.ModifiedCount > 0 {
+		id = before["_id"]
+	} else {
+		return res, nil
+	}
+
+	after, err := coll.findDocument(bson.M{"_id": id})
+	if err != nil {
+		return res, err
+	}
+
+	return res, coll.recordChange(op, id, filter, before, after)
+}
+
+// trackedDeleteOne - DeleteOne of a tracked model, see trackedUpdate
+func (coll *ModelOf[T, ID]) trackedDeleteOne(filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	opt := options.MergeDeleteOptions(opts...)
+	readOpt := readOptions(opt.Collation, opt.Hint)
+
+	for attempt := 1; ; attempt++ {
+		before, err := coll.findDocument(filter, readOpt)
+		if err != nil {
+			return nil, err
+		}
+		if before == nil {
+			return coll.Native().DeleteOne(coll.ctx(), filter, opts...)
+		}
+
+		res, err := coll.Native().DeleteOne(coll.ctx(), readTarget(filter, before), opts...)
+		if err != nil {
+			return res, err
+		}
+		if res.DeletedCount > 0 {
+			return res, coll.recordChange(AuditDelete, before["_id"], filter, before, nil)
+		}
+		if attempt == trackedAttempts {
+			return res, nil
+		}
+	}
+}
+
+// trackedInsertMany - InsertMany of a tracked model
+func (coll *ModelOf[T, ID]) trackedInsertMany(docs []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	res, err := coll.Native().InsertMany(coll.ctx(), docs, opts...)
+	if err != nil {
+		return res, err
+	}
+
+	for i, id := range res.InsertedIDs {
+		after, err := toDocument(coll.registry(), docs[i])
+		if err != nil {
+			return res, err
+		}
+		after["_id"] = id
+
+		if err = coll.recordChange(AuditInsert, id, nil, nil, after); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// findDocuments - Find documents as bson.M
+func (coll *ModelOf[T, ID]) findDocuments(filter interface{}, opts ...*options.FindOptions) ([]bson.M, error) {
+	cursor, err := coll.Native().Find(coll.ctx(), filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]bson.M, 0)
+	err = cursor.All(coll.ctx(), &docs)
+	return docs, err
+}
+
+// readManyOptions - readOptions of the read before a tracked write of many documents
+func readManyOptions(collation *options.Collation, hint interface{}) *options.FindOptions {
+	opt := options.Find()
+	if collation != nil {
+		opt.SetCollation(collation)
+	}
+	if hint != nil {
+		opt.SetHint(hint)
+	}
+	return opt
+}
+
+// readIDs - The _id of documents that were read
+func readIDs(docs []bson.M) bson.A {
+	ids := make(bson.A, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc["_id"]
+	}
+	return ids
+}
+
+// readTargets - Filter of the documents that were read, still matching filter
+func readTargets(filter interface{}, before []bson.M) bson.M {
+	if filter == nil {
+		filter = bson.M{}
+	}
+	return bson.M{"$and": bson.A{filter, bson.M{"_id": bson.M{"$in": readIDs(before)}}}}
+}
+
+// documentsByID - Index documents by their _id, ids such as sub documents
+// are not comparable so they are keyed by their encoded form
+func (coll *ModelOf[T, ID]) documentsByID(docs []bson.M) (map[string]bson.M, error) {
+	byID := make(map[string]bson.M, len(docs))
+	for _, doc := range docs {
+		key, err := coll.idKey(doc["_id"])
+		if err != nil {
+			return nil, err
+		}
+		byID[key] = doc
+	}
+	return byID, nil
+}
+
+// idKey - Key of an _id in documentsByID
+func (coll *ModelOf[T, ID]) idKey(id interface{}) (string, error) {
+	raw, err := marshalValueWith(coll.registry(), id)
+	if err != nil {
+		return "", err
+	}
+	return string(raw.Type) + string(raw.Value), nil
+}
+
+// trackedUpdateMany - UpdateMany of a tracked model. The matching documents
+// are read first and only they are updated, so each of them is recorded.
+func (coll *ModelOf[T, ID]) trackedUpdateMany(filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	opt := options.MergeUpdateOptions(opts...)
+	before, err := coll.findDocuments(filter, readManyOptions(opt.Collation, opt.Hint))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(before) == 0 {
+		// nothing to record unless it upserts
+		res, err := coll.Native().UpdateMany(coll.ctx(), filter, update, opts...)
+		if err != nil || res.UpsertedID == nil {
+			return res, err
+		}
+
+		after, err := coll.findDocument(bson.M{"_id": res.UpsertedID})
+		if err != nil {
+			return res, err
+		}
+		return res, coll.recordChange(AuditInsert, res.UpsertedID, filter, nil, after)
+	}
+
+	res, err := coll.Native().UpdateMany(coll.ctx(), readTargets(filter, before), update, opt, options.Update().SetUpsert(false))
+	if err != nil || res.ModifiedCount == 0 {
+		return res, err
+	}
+
+	after, err := coll.findDocuments(bson.M{"_id": bson.M{"$in": readIDs(before)}})
+	if err != nil {
+		return res, err
+	}
+	afterByID, err := coll.documentsByID(after)
+	if err != nil {
+		return res, err
+	}
+
+	for _, doc := range before {
+		key, err := coll.idKey(doc["_id"])
+		if err != nil {
+			return res, err
+		}
+
+		// unchanged documents were not modified by the update
+		changed, ok := afterByID[key]
+		if !ok || reflect.DeepEqual(doc, changed) {
+			continue
+		}
+		if err = coll.recordChange(AuditUpdate, doc["_id"], filter, doc, changed); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// trackedDeleteMany - DeleteMany of a tracked model, see trackedUpdateMany
+func (coll *ModelOf[T, ID]) trackedDeleteMany(filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	opt := options.MergeDeleteOptions(opts...)
+	before, err := coll.findDocuments(filter, readManyOptions(opt.Collation, opt.Hint))
+	if err != nil {
+		return nil, err
+	}
+	if len(before) == 0 {
+		return coll.Native().DeleteMany(coll.ctx(), filter, opts...)
+	}
+
+	res, err := coll.Native().DeleteMany(coll.ctx(), readTargets(filter, before), opts...)
+	if err != nil || res.DeletedCount == 0 {
+		return res, err
+	}
+
+	// documents deleted by another write in between are not recorded
+	remainingByID := map[string]bson.M{}
+	if res.DeletedCount < int64(len(before)) {
+		remaining, err := coll.findDocuments(bson.M{"_id": bson.M{"$in": readIDs(before)}})
+		if err != nil {
+			return res, err
+		}
+		if remainingByID, err = coll.documentsByID(remaining); err != nil {
+			return res, err
+		}
+	}
+
+	for _, doc := range before {
+		key, err := coll.idKey(doc["_id"])
+		if err != nil {
+			return res, err
+		}
+		if _, ok := remainingByID[key]; ok {
+			continue
+		}
+		if err = coll.recordChange(AuditDelete, doc["_id"], filter, doc, nil); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// errTrackedProjection - Returned by FindOneAnd writes of tracked models with a projection
+var errTrackedProjection = errors.New("gmongo: FindOneAnd writes of models with audit or history cannot use a projection")
+
+// returnDocument - The options.ReturnDocument of after
+func returnDocument(after bool) options.ReturnDocument {
+	if after {
+		return options.After
+	}
+	return options.Before
+}
+
+// trackedFindOneAnd - Run a FindOneAndUpdate or FindOneAndReplace of a
+// tracked model, recording the document before and after it.
+//
+// write runs the command with filter, whether it may upsert and whether it
+// returns the document after the write. The document before the write is
+// returned by the write itself, so it is the one that was replaced.
+func (coll *ModelOf[T, ID]) trackedFindOneAnd(filter interface{}, upsert, returnAfter bool, result interface{}, write func(filter interface{}, upsert, after bool) *mongo.SingleResult) error {
+	res := write(filter, false, false)
+	if IsNoDocumentsError(res.Err()) && upsert {
+		// nothing matched, the inserted document is returned to know its _id
+		res = write(filter, true, true)
+
+		var after bson.M
+		if err := res.Decode(&after); err != nil {
+			return err
+		}
+		if err := coll.recordChange(AuditInsert, after["_id"], filter, nil, after); err != nil {
+			return err
+		}
+
+		// like the driver, there is no document before an upsert
+		if !returnAfter {
+			return mongo.ErrNoDocuments
+		}
+		return coll.decodeResult(res, result)
+	}
+
+	var before bson.M
+	if err := res.Decode(&before); err != nil {
+		return err
+	}
+
+	afterRes := coll.Native().FindOne(coll.ctx(), bson.M{"_id": before["_id"]})
+	var after bson.M
+	if err := afterRes.Decode(&after); err != nil && !IsNoDocumentsError(err) {
+		return err
+	}
+	if !reflect.DeepEqual(before, after) {
+		if err := coll.recordChange(AuditUpdate, before["_id"], filter, before, after); err != nil {
+			return err
+		}
+	}
+
+	if returnAfter {
+		res = afterRes
+	}
+	return coll.decodeResult(res, result)
+}
+
+// trackedFindOneAndDelete - FindOneAndDelete of a tracked model
+func (coll *ModelOf[T, ID]) trackedFindOneAndDelete(filter interface{}, result interface{}, opts ...*options.FindOneAndDeleteOptions) error {
+	res := coll.Native().FindOneAndDelete(coll.ctx(), filter, opts...)
+
+	var before bson.M
+	if err := res.Decode(&before); err != nil {
+		return err
+	}
+	if err := coll.recordChange(AuditDelete, before["_id"], filter, before, nil); err != nil {
+		return err
+	}
+
+	return coll.decodeResult(res, result)
+}
@@ -0,0 +1,474 @@
+package gmongo
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Tx - A transaction handle passed to the callback of Client.Transaction.
+// Use Tx.Context() to enroll raw mongo-driver calls in the transaction, or
+// Tx.Collection(name) for native access to a collection that has no Model.
+type Tx struct {
+	sc      mongo.SessionContext
+	db      *mongo.Database
+	client  *Client
+	session mongo.Session
+
+	// manual is set for transactions started with Client.Begin, which are
+	// ended by Commit or Abort instead of by Client.Transaction.
+	manual bool
+	done   bool
+
+	afterCommit   []func()
+	afterRollback []func(err error)
+
+	// rollbackOnly is set when a joined transaction fails, so the outer
+	// transaction is aborted even if its callback swallows the error.
+	rollbackOnly error
+}
+
+// txContextKey - Context key of the current *Tx
+type txContextKey struct{}
+
+var (
+	// ErrTxAborted - Passed to AfterRollback callbacks when a transaction is aborted with Tx.Abort
+	ErrTxAborted = errors.New("gmongo: transaction aborted")
+	// ErrTxDone - Returned by Commit and Abort when the transaction is already committed or aborted
+	ErrTxDone = errors.New("gmongo: transaction has already been committed or aborted")
+	// ErrTxNotManual - Returned by Commit and Abort on a transaction managed by Client.Transaction
+	ErrTxNotManual = errors.New("gmongo: Commit and Abort can only be called on a transaction started with Client.Begin")
+)
+
+// Context returns the session context. Pass it to any raw mongo-driver call
+// to enroll that op in the transaction.
+func (t *Tx) Context() mongo.SessionContext { return t.sc }
+
+// Database returns the *mongo.Database the transaction is running on.
+func (t *Tx) Database() *mongo.Database { return t.db }
+
+// Collection is sugar for tx.Database().Collection(name) — convenient for
+// native ops on collections that don't have a gmongo Model.
+func (t *Tx) Collection(name string) *mongo.Collection { return t.db.Collection(name) }
+
+// AfterCommit registers fn to run once the transaction is committed, e.g. to
+// publish events or invalidate caches. Callbacks run in registration order.
+//
+// Only the callbacks registered by the final attempt run, so retries of the
+// transaction callback don't run them more than once.
+func (t *Tx) AfterCommit(fn func()) { t.afterCommit = append(t.afterCommit, fn) }
+
+// AfterRollback registers fn to run once the transaction is given up on,
+// with the error that aborted it. Like AfterCommit, only the callbacks of the
+// final attempt run.
+func (t *Tx) AfterRollback(fn func(err error)) { t.afterRollback = append(t.afterRollback, fn) }
+
+// join - Run fn as part of this transaction
+func (t *Tx) join(opt TxOptions, fn func(tx *Tx) error) error {
+	if opt.OnCommit != nil {
+		t.AfterCommit(opt.OnCommit)
+	}
+	if opt.OnAbort != nil {
+		t.AfterRollback(opt.OnAbort)
+	}
+
+	err := fn(t)
+	if err != nil && t.rollbackOnly == nil {
+		t.rollbackOnly = err
+	}
+	return err
+}
+
+// committed - Run the AfterCommit callbacks
+func (t *Tx) committed() {
+	for _, fn := range t.afterCommit {
+		fn()
+	}
+}
+
+// rolledBack - Run the AfterRollback callbacks
+func (t *Tx) rolledBack(err error) {
+	for _, fn := range t.afterRollback {
+		fn(err)
+	}
+}
+
+// TxOptions - Retry policy and hooks of a transaction
+type TxOptions struct {
+	// MaxRetries - How many times the transaction is retried after the first attempt
+	MaxRetries int
+	// Backoff - Delay before the first retry, doubled on every retry with jitter
+	Backoff time.Duration
+	// MaxBackoff - Upper bound of the delay between retries
+	MaxBackoff time.Duration
+	// Timeout - Overall time limit of all attempts, 0 means no limit
+	Timeout time.Duration
+	// RetryIf - Decide if a failed attempt is retried, defaults to IsTransientTxError
+	RetryIf func(err error) bool
+
+	// OnRetry - Called before each retry with the attempt that failed (starting at 1)
+	OnRetry func(attempt int, err error)
+	// OnCommit - Called once the transaction is committed
+	OnCommit func()
+	// OnAbort - Called once the transaction is given up on, with the final error
+	OnAbort func(err error)
+
+	// Transaction - Read concern, write concern and read preference of the transaction
+	Transaction *options.TransactionOptions
+
+	// Propagation - What to do when called inside another transaction, defaults to TxRequired
+	Propagation TxPropagation
+}
+
+// TxPropagation - How a transaction behaves when started inside another transaction
+type TxPropagation int
+
+const (
+	// TxRequired - Join the transaction of the context if there is one, otherwise start a new one
+	TxRequired TxPropagation = iota
+	// TxRequiresNew - Always start a new transaction in its own session
+	TxRequiresNew
+)
+
+// DefaultTxOptions - Options used by Client.Transaction
+var DefaultTxOptions = TxOptions{
+	MaxRetries: 5,
+	Backoff:    10 * time.Millisecond,
+	MaxBackoff: time.Second,
+}
+
+// backoff - Get the delay before retrying after the given failed attempt
+func (o TxOptions) backoff(attempt int) time.Duration {
+	if o.Backoff <= 0 {
+		return 0
+	}
+
+	delay := o.Backoff << (attempt - 1)
+	if delay <= 0 || (o.MaxBackoff > 0 && delay > o.MaxBackoff) {
+		delay = o.MaxBackoff
+	}
+
+	// full jitter on the upper half to spread out competing transactions
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// TxFromContext returns the transaction of this client that ctx belongs to, if any.
+// Contexts derived from tx.Context() belong to tx.
+func (c *Client) TxFromContext(ctx context.Context) (*Tx, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	tx, ok := ctx.Value(txContextKey{}).(*Tx)
+	if !ok || tx.client != c {
+		return nil, false
+	}
+
+	return tx, true
+}
+
+// Transaction runs fn inside a MongoDB transaction. The session lifecycle is
+// managed for the caller. Return an error from fn to abort; nil to commit.
+//
+// Inside fn, use Model[T].WithTx(tx) to get a transaction-bound model, or
+// tx.Context() / tx.Collection(name) for native mongo-driver access.
+//
+// Transient errors are retried with DefaultTxOptions, use TransactionWith for
+// a different retry policy.
+//
+// Transaction has no parent context, so it always starts a new transaction,
+// even when called inside another one. Use TransactionContext to join the
+// transaction of a context instead.
+//
+// Note: MongoDB transactions require a replica set or sharded cluster.
+func (c *Client) Transaction(fn func(tx *Tx) error, opts ...*options.TransactionOptions) error {
+	return c.TransactionContext(context.TODO(), fn, opts...)
+}
+
+// TransactionContext runs fn inside a MongoDB transaction, see Transaction.
+// If ctx belongs to a transaction of this client, fn joins it instead of
+// starting a new one:
+//
+//	func (s *Service) CreateOrder(ctx context.Context, order *Order) error {
+//		return s.client.TransactionContext(ctx, func(tx *gmongo.Tx) error {
+//			// joins the caller's transaction when called with a tx.Context()
+//		})
+//	}
+//
+// When joined, an error returned by fn aborts the outer transaction even if
+// the outer callback ignores it.
+//
+// Models are not enrolled through ctx on their own: bind them with
+// Model[T].WithTx(tx) or Model[T].WithContext(ctx).
+func (c *Client) TransactionContext(ctx context.Context, fn func(tx *Tx) error, opts ...*options.TransactionOptions) error {
+	opt := DefaultTxOptions
+	opt.Transaction = options.MergeTransactionOptions(opts...)
+	return c.TransactionWithContext(ctx, opt, fn)
+}
+
+// TransactionWith runs fn inside a MongoDB transaction using the given retry policy and hooks.
+//
+// The whole transaction (fn and commit) is retried while opt.RetryIf returns true,
+// so fn must be safe to run more than once.
+//
+//	err := client.TransactionWith(gmongo.TxOptions{
+//		MaxRetries: 3,
+//		Backoff:    50 * time.Millisecond,
+//		Timeout:    5 * time.Second,
+//		OnRetry: func(attempt int, err error) {
+//			log.Printf("transaction attempt %d failed: %v", attempt, err)
+//		},
+//	}, func(tx *gmongo.Tx) error {
+//		...
+//	})
+//
+// Like Transaction, TransactionWith always starts a new transaction, use
+// TransactionWithContext to join the transaction of a context instead.
+func (c *Client) TransactionWith(opt TxOptions, fn func(tx *Tx) error) error {
+	return c.TransactionWithContext(context.TODO(), opt, fn)
+}
+
+// TransactionWithContext is TransactionWith with a parent context, see TransactionContext.
+//
+// When fn joins an outer transaction, the retry policy is left to the outer
+// transaction and OnCommit/OnAbort run on its outcome.
+func (c *Client) TransactionWithContext(ctx context.Context, opt TxOptions, fn func(tx *Tx) error) error {
+	if opt.Propagation == TxRequired {
+		if outer, ok := c.TxFromContext(ctx); ok {
+			return outer.join(opt, fn)
+		}
+	}
+
+	if opt.RetryIf == nil {
+		opt.RetryIf = IsTransientTxError
+	}
+
+	if opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.Timeout)
+		defer cancel()
+	}
+
+	session, err := c.MongoClient.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.WithoutCancel(ctx))
+
+	// the callbacks registered by the final attempt run on the outcome
+	var tx *Tx
+	abort := func(err error) error {
+		if tx != nil {
+			tx.rolledBack(err)
+		}
+		if opt.OnAbort != nil {
+			opt.OnAbort(err)
+		}
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		tx, err = c.runTransaction(ctx, session, opt, fn)
+		if err == nil {
+			tx.committed()
+			if opt.OnCommit != nil {
+				opt.OnCommit()
+			}
+			return nil
+		}
+
+		if attempt > opt.MaxRetries || !opt.RetryIf(err) || ctx.Err() != nil {
+			return abort(err)
+		}
+
+		if opt.OnRetry != nil {
+			opt.OnRetry(attempt, err)
+		}
+
+		select {
+		case <-time.After(opt.backoff(attempt)):
+		case <-ctx.Done():
+			return abort(ctx.Err())
+		}
+	}
+}
+
+// runTransaction - Run a single attempt of a transaction
+func (c *Client) runTransaction(ctx context.Context, session mongo.Session, opt TxOptions, fn func(tx *Tx) error) (*Tx, error) {
+	if err := session.StartTransaction(opt.Transaction); err != nil {
+		return nil, err
+	}
+
+	tx := c.newTx(ctx, session)
+
+	err := fn(tx)
+	if err == nil {
+		err = tx.rollbackOnly
+	}
+	if err != nil {
+		// abort even if ctx timed out, otherwise the transaction holds its locks until it expires
+		_ = session.AbortTransaction(context.WithoutCancel(ctx))
+		return tx, err
+	}
+
+	for commit := 0; ; commit++ {
+		err := session.CommitTransaction(tx.sc)
+		if err == nil {
+			return tx, nil
+		}
+
+		// the commit may or may not have been applied, committing again is safe
+		if isUnknownCommitResult(err) && commit < opt.MaxRetries && ctx.Err() == nil {
+			continue
+		}
+
+		_ = session.AbortTransaction(context.WithoutCancel(ctx))
+		return tx, err
+	}
+}
+
+// newTx - Create the handle of a transaction started on session
+func (c *Client) newTx(ctx context.Context, session mongo.Session) *Tx {
+	tx := &Tx{db: c.Database, client: c, session: session}
+	tx.sc = mongo.NewSessionContext(context.WithValue(ctx, txContextKey{}, tx), session)
+	return tx
+}
+
+// Begin starts a transaction that is ended explicitly with tx.Commit or tx.Abort,
+// for units of work that don't fit in a callback (e.g. streaming imports).
+//
+// Unlike Client.Transaction, nothing is retried; check IsTransientTxError on
+// the returned errors to decide whether to start over.
+//
+//	tx, err := client.Begin(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer tx.Abort(ctx) // no-op once committed
+//
+//	for batch := range batches {
+//		if _, err := ItemModel.WithTx(tx).InsertMany(batch); err != nil {
+//			return err
+//		}
+//	}
+//
+//	return tx.Commit(ctx)
+func (c *Client) Begin(ctx context.Context, opts ...*options.TransactionOptions) (*Tx, error) {
+	session, err := c.MongoClient.StartSession()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = session.StartTransaction(options.MergeTransactionOptions(opts...)); err != nil {
+		session.EndSession(ctx)
+		return nil, err
+	}
+
+	tx := c.newTx(ctx, session)
+	tx.manual = true
+	return tx, nil
+}
+
+// Commit commits a transaction started with Client.Begin and ends its session.
+// If the commit fails, the transaction is aborted.
+func (t *Tx) Commit(ctx context.Context) error {
+	if !t.manual {
+		return ErrTxNotManual
+	}
+	if t.done {
+		return ErrTxDone
+	}
+	t.done = true
+	defer t.session.EndSession(context.WithoutCancel(ctx))
+
+	sc := mongo.NewSessionContext(ctx, t.session)
+	err := t.rollbackOnly
+	for commit := 0; err == nil; commit++ {
+		err = t.session.CommitTransaction(sc)
+		if err == nil {
+			t.committed()
+			return nil
+		}
+
+		// the commit may or may not have been applied, committing again is safe
+		if isUnknownCommitResult(err) && commit < DefaultTxOptions.MaxRetries && ctx.Err() == nil {
+			err = nil
+		}
+	}
+
+	_ = t.session.AbortTransaction(context.WithoutCancel(ctx))
+	t.rolledBack(err)
+	return err
+}
+
+// Abort aborts a transaction started with Client.Begin and ends its session.
+// Calling Abort after Commit is a no-op, so it can be deferred.
+func (t *Tx) Abort(ctx context.Context) error {
+	if !t.manual {
+		return ErrTxNotManual
+	}
+	if t.done {
+		return nil
+	}
+	t.done = true
+	defer t.session.EndSession(context.WithoutCancel(ctx))
+
+	err := t.session.AbortTransaction(mongo.NewSessionContext(ctx, t.session))
+	t.rolledBack(ErrTxAborted)
+	return err
+}
+
+// Session - A causally consistent session without a transaction, passed to the callback of Client.Session.
+//
+// Reads through a session see the writes made earlier in it, even on secondaries.
+type Session struct {
+	sc mongo.SessionContext
+	db *mongo.Database
+}
+
+// Context returns the session context. Pass it to any raw mongo-driver call
+// to run that op in the session.
+func (s *Session) Context() mongo.SessionContext { return s.sc }
+
+// Database returns the *mongo.Database the session is running on.
+func (s *Session) Database() *mongo.Database { return s.db }
+
+// Collection is sugar for s.Database().Collection(name).
+func (s *Session) Collection(name string) *mongo.Collection { return s.db.Collection(name) }
+
+// Session runs fn in a causally consistent session, so reads see the writes
+// made before them in fn, e.g. when reading from secondaries. Unlike
+// Transaction, writes are not atomic and are not rolled back if fn fails.
+//
+//	err := client.Session(func(s *gmongo.Session) error {
+//		users := UserModel.WithSession(s)
+//		if _, err := users.UpdateOne(filter, update); err != nil {
+//			return err
+//		}
+//		user, err := users.FindOne(filter, options.FindOne()) // sees the update
+//		...
+//	})
+//
+// Session has no parent context, use SessionContext for deadlines and cancellation.
+func (c *Client) Session(fn func(s *Session) error, opts ...*options.SessionOptions) error {
+	return c.SessionContext(context.TODO(), fn, opts...)
+}
+
+// SessionContext is Session with a parent context: the session context passed
+// to fn, and so the operations run in the session, derive from ctx.
+func (c *Client) SessionContext(ctx context.Context, fn func(s *Session) error, opts ...*options.SessionOptions) error {
+	opt := options.MergeSessionOptions(append([]*options.SessionOptions{options.Session().SetCausalConsistency(true)}, opts...)...)
+
+	session, err := c.MongoClient.StartSession(opt)
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.WithoutCancel(ctx))
+
+	return fn(&Session{sc: mongo.NewSessionContext(ctx, session), db: c.Database})
+}
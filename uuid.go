@@ -0,0 +1,140 @@
+package gmongo
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// UUID - A UUID stored as BSON binary subtype 4 and marshalled to JSON as
+// its canonical string, e.g. "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+type UUID [16]byte
+
+// uuidType - The reflect type of UUID, used to register its codec
+var uuidType = reflect.TypeOf(UUID{})
+
+// NewUUID - Generate a new random (version 4) UUID
+func NewUUID() UUID {
+	return UUID(uuid.New())
+}
+
+// ParseUUID - Parse a UUID from its string form
+func ParseUUID(s string) (UUID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return UUID{}, err
+	}
+	return UUID(id), nil
+}
+
+// MustParseUUID - Parse a UUID from its string form, panics if it is invalid
+func MustParseUUID(s string) UUID {
+	return UUID(uuid.MustParse(s))
+}
+
+// String - Get the canonical string form of the UUID
+func (u UUID) String() string {
+	return uuid.UUID(u).String()
+}
+
+// IsZero - Check if the UUID is unset
+func (u UUID) IsZero() bool {
+	return u == UUID{}
+}
+
+// Binary - Get the UUID as BSON binary subtype 4
+func (u UUID) Binary() primitive.Binary {
+	return primitive.Binary{Subtype: bsontype.BinaryUUID, Data: u[:]}
+}
+
+// MarshalText - Marshal the UUID as its canonical string, used by encoding/json
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText - Parse the UUID from its canonical string, used by encoding/json
+func (u *UUID) UnmarshalText(data []byte) error {
+	id, err := ParseUUID(string(data))
+	if err != nil {
+		return err
+	}
+	*u = id
+	return nil
+}
+
+// MarshalBSONValue - Marshal the UUID as binary subtype 4, so it is encoded
+// the same with or without the registry of NewRegistry
+func (u UUID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.TypeBinary, bsoncore.AppendBinary(nil, bsontype.BinaryUUID, u[:]), nil
+}
+
+// UnmarshalBSONValue - Unmarshal the UUID from binary subtype 4 (or legacy
+// subtype 3), or from its string form to read UUIDs stored as strings
+func (u *UUID) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	switch t {
+	case bson.TypeBinary:
+		subtype, bin, _, ok := bsoncore.ReadBinary(data)
+		if !ok {
+			return fmt.Errorf("gmongo: invalid binary UUID")
+		}
+		if subtype != bsontype.BinaryUUID && subtype != bsontype.BinaryUUIDOld {
+			return fmt.Errorf("gmongo: cannot decode binary subtype %d into a UUID", subtype)
+		}
+		if len(bin) != len(u) {
+			return fmt.Errorf("gmongo: UUID must be %d bytes, got %d", len(u), len(bin))
+		}
+		copy(u[:], bin)
+		return nil
+	case bson.TypeString:
+		s, _, ok := bsoncore.ReadString(data)
+		if !ok {
+			return fmt.Errorf("gmongo: invalid string UUID")
+		}
+		return u.UnmarshalText([]byte(s))
+	case bson.TypeNull, bson.TypeUndefined:
+		*u = UUID{}
+		return nil
+	default:
+		return fmt.Errorf("gmongo: cannot decode %s into a UUID", t)
+	}
+}
+
+// UUIDCodec - The BSON codec of UUID
+type UUIDCodec struct{}
+
+// EncodeValue - Encode a UUID as binary subtype 4
+func (UUIDCodec) EncodeValue(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Type() != uuidType {
+		return bsoncodec.ValueEncoderError{Name: "UUIDCodec.EncodeValue", Types: []reflect.Type{uuidType}, Received: val}
+	}
+
+	id := val.Interface().(UUID)
+	return vw.WriteBinaryWithSubtype(id[:], bsontype.BinaryUUID)
+}
+
+// DecodeValue - Decode a UUID, see UUID.UnmarshalBSONValue
+func (UUIDCodec) DecodeValue(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != uuidType {
+		return bsoncodec.ValueDecoderError{Name: "UUIDCodec.DecodeValue", Types: []reflect.Type{uuidType}, Received: val}
+	}
+
+	t, data, err := bsonrw.Copier{}.CopyValueToBytes(vr)
+	if err != nil {
+		return err
+	}
+
+	var id UUID
+	if err = id.UnmarshalBSONValue(t, data); err != nil {
+		return err
+	}
+
+	val.Set(reflect.ValueOf(id))
+	return nil
+}
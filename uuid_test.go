@@ -0,0 +1,98 @@
+package gmongo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UUIDSession struct {
+	ID     UUID  `bson:"_id" json:"id"`
+	UserID *UUID `bson:"userId,omitempty" json:"userId,omitempty"`
+}
+
+func (s *UUIDSession) GetID() UUID { return s.ID }
+
+func TestUUID_JSON(t *testing.T) {
+	id := MustParseUUID("f47ac10b-58cc-4372-a567-0e02b2c3d479")
+
+	data, err := json.Marshal(UUIDSession{ID: id})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"id":"f47ac10b-58cc-4372-a567-0e02b2c3d479"}`, string(data))
+
+	var decoded UUIDSession
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, id, decoded.ID)
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"id":"nope"}`), &decoded))
+}
+
+func TestUUID_BSON(t *testing.T) {
+	id := NewUUID()
+	userID := NewUUID()
+
+	for name, marshal := range map[string]func(v interface{}) ([]byte, error){
+		"Default registry": bson.Marshal,
+		"gmongo registry": func(v interface{}) ([]byte, error) {
+			return bson.MarshalWithRegistry(NewRegistry(), v)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := marshal(UUIDSession{ID: id, UserID: &userID})
+			assert.Nil(t, err)
+
+			// stored as binary subtype 4
+			raw := bson.Raw(data)
+			subtype, bin := raw.Lookup("_id").Binary()
+			assert.Equal(t, bsontype.BinaryUUID, subtype)
+			assert.Equal(t, id[:], bin)
+
+			var decoded UUIDSession
+			assert.Nil(t, bson.UnmarshalWithRegistry(NewRegistry(), data, &decoded))
+			assert.Equal(t, id, decoded.ID)
+			assert.Equal(t, userID, *decoded.UserID)
+		})
+	}
+
+	t.Run("Decodes strings", func(t *testing.T) {
+		data, _ := bson.Marshal(bson.M{"_id": id.String()})
+
+		var decoded UUIDSession
+		assert.Nil(t, bson.Unmarshal(data, &decoded))
+		assert.Equal(t, id, decoded.ID)
+	})
+
+	t.Run("Rejects other binary subtypes", func(t *testing.T) {
+		data, _ := bson.Marshal(bson.M{"_id": primitive.Binary{Subtype: 0, Data: id[:]}})
+
+		var decoded UUIDSession
+		assert.NotNil(t, bson.Unmarshal(data, &decoded))
+	})
+}
+
+func TestUUID_Model(t *testing.T) {
+	client := testConnectToDb()
+	SessionModel := MakeModelOf[*UUIDSession, UUID](client.Database, "uuid_sessions")
+	_, _ = SessionModel.DeleteMany(bson.M{})
+
+	session := &UUIDSession{ID: NewUUID()}
+	_, err := SessionModel.InsertOne(session)
+	assert.Nil(t, err)
+
+	found, err := SessionModel.FindOneById(session.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, session.ID, found.ID)
+
+	// queries by the binary form match too
+	count, err := SessionModel.Count(bson.M{"_id": session.ID.Binary()})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	deleted, err := SessionModel.Helpers(found).Delete()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, deleted.DeletedCount)
+}
@@ -0,0 +1,295 @@
+package gmongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultResumeTokenCollection - Collection of resume tokens when SubscribeOptions.TokenCollection is empty
+const DefaultResumeTokenCollection = "gmongo_resume_tokens"
+
+// UpdateDescription - Fields changed by an update event
+type UpdateDescription struct {
+	UpdatedFields bson.M   `bson:"updatedFields" json:"updatedFields"`
+	RemovedFields []string `bson:"removedFields" json:"removedFields"`
+}
+
+// ChangeEvent - A change stream event of a Model[T] collection
+type ChangeEvent[T any] struct {
+	// ResumeToken - Pass to options.ChangeStream().SetResumeAfter to resume after this event
+	ResumeToken   bson.Raw `bson:"_id" json:"-"`
+	OperationType string   `bson:"operationType" json:"operationType"`
+	// FullDocument - The document after the change, set for inserts and replaces,
+	// and for updates when the stream is opened with options.UpdateLookup
+	FullDocument T `bson:"fullDocument" json:"fullDocument"`
+	// FullDocumentBeforeChange - The document before the change, set when the
+	// stream is opened with SetFullDocumentBeforeChange and the collection
+	// records pre-images
+	FullDocumentBeforeChange T                   `bson:"fullDocumentBeforeChange,omitempty" json:"fullDocumentBeforeChange,omitempty"`
+	DocumentKey              bson.M              `bson:"documentKey" json:"documentKey"`
+	UpdateDescription        *UpdateDescription  `bson:"updateDescription,omitempty" json:"updateDescription,omitempty"`
+	ClusterTime              primitive.Timestamp `bson:"clusterTime" json:"clusterTime"`
+}
+
+// ChangeStream - A change stream decoding events of a Model[T] collection
+type ChangeStream[T any] struct {
+	*mongo.ChangeStream
+	// encryption - Decrypts the documents of events when encryption is enabled
+	encryption *fieldEncryption
+}
+
+// Event - Decode the current event, decrypting its documents when encryption is enabled
+func (cs *ChangeStream[T]) Event() (*ChangeEvent[T], error) {
+	var event ChangeEvent[T]
+	if cs.encryption == nil {
+		if err := cs.Decode(&event); err != nil {
+			return nil, err
+		}
+		return &event, nil
+	}
+
+	if err := cs.encryption.decryptRaw(cs.Current, &event); err != nil {
+		return nil, err
+	}
+	// keep the token as the server sent it
+	if token, ok := cs.Current.Lookup("_id").DocumentOK(); ok {
+		event.ResumeToken = token
+	}
+	return &event, nil
+}
+
+// Watch - Open a change stream on the collection of the model
+//
+//	stream, err := UserModel.Watch(ctx, nil, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+//	if err != nil {
+//		return err
+//	}
+//	defer stream.Close(ctx)
+//
+//	for stream.Next(ctx) {
+//		event, err := stream.Event()
+//		...
+//	}
+func (coll *ModelOf[T, ID]) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*ChangeStream[T], error) {
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{}
+	}
+
+	stream, err := coll.Native().Watch(ctx, pipeline, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := &ChangeStream[T]{ChangeStream: stream}
+	if coll.encryption != nil {
+		cs.encryption = coll.encrypter()
+	}
+	return cs, nil
+}
+
+// SubscribeOptions - Options of Model[T].Subscribe
+type SubscribeOptions struct {
+	// Name - Identifies the subscription in the token collection.
+	// Without a name, resume tokens are only kept in memory.
+	Name string
+	// TokenCollection - Collection resume tokens are saved to, defaults to DefaultResumeTokenCollection
+	TokenCollection string
+	// Pipeline - Optional pipeline filtering the events
+	Pipeline interface{}
+	// FullDocument - Full document mode, defaults to options.UpdateLookup
+	FullDocument options.FullDocument
+	// RetryDelay - Delay before restarting after an error, doubled up to MaxRetryDelay, defaults to 1s
+	RetryDelay time.Duration
+	// MaxRetryDelay - Upper bound of the delay before restarting, defaults to 1m
+	MaxRetryDelay time.Duration
+	// OnError - Called with errors the subscription recovers from
+	OnError func(err error)
+}
+
+// Subscription - A change stream running on a goroutine, see Model[T].Subscribe
+type Subscription struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Stop - Stop the subscription and wait for the running handler to return
+func (s *Subscription) Stop() {
+	s.cancel()
+	<-s.done
+}
+
+// Wait - Wait for the subscription to end and return the error that ended it
+func (s *Subscription) Wait() error {
+	<-s.done
+	return s.err
+}
+
+// EventDecodeError - A change event that could not be decoded into a
+// ChangeEvent[T], e.g. a document that doesn't match T. Subscribe reports it
+// to OnError and skips the event.
+type EventDecodeError struct {
+	ResumeToken bson.Raw
+	Err         error
+}
+
+func (e *EventDecodeError) Error() string {
+	return "gmongo: cannot decode change event: " + e.Err.Error()
+}
+
+func (e *EventDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// isFatalWatchError - Check if the change stream cannot be resumed, e.g. the
+// resume token fell off the oplog
+func isFatalWatchError(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && (serverErr.HasErrorCode(286) || serverErr.HasErrorCode(280))
+}
+
+// Subscribe - Run handler on a goroutine for every change of the collection.
+//
+// The resume token is saved after each handled event, so a restarted process
+// (or a stream interrupted by a transient error) resumes where it left off.
+// If the handler returns an error, the stream restarts after RetryDelay from
+// the last handled event, so events are delivered at least once. Events that
+// cannot be decoded are skipped and reported to OnError as *EventDecodeError,
+// since they would fail again after a restart.
+//
+// The subscription ends when ctx is cancelled, Stop is called, or the stream
+// cannot be resumed (see Wait).
+//
+//	sub := UserModel.Subscribe(ctx, func(ctx context.Context, event *gmongo.ChangeEvent[*User]) error {
+//		return search.Index(event.FullDocument)
+//	}, gmongo.SubscribeOptions{Name: "user-search-indexer"})
+//	defer sub.Stop()
+func (coll *ModelOf[T, ID]) Subscribe(ctx context.Context, handler func(ctx context.Context, event *ChangeEvent[T]) error, opt SubscribeOptions) *Subscription {
+	if opt.TokenCollection == "" {
+		opt.TokenCollection = DefaultResumeTokenCollection
+	}
+	if opt.FullDocument == "" {
+		opt.FullDocument = options.UpdateLookup
+	}
+	if opt.RetryDelay <= 0 {
+		opt.RetryDelay = time.Second
+	}
+	if opt.MaxRetryDelay <= 0 {
+		opt.MaxRetryDelay = time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	sub := &Subscription{cancel: cancel, done: make(chan struct{})}
+
+	go func() {
+		defer close(sub.done)
+		sub.err = coll.runSubscription(ctx, handler, opt)
+	}()
+
+	return sub
+}
+
+// runSubscription - Watch the collection, restarting after errors, until ctx is done
+func (coll *ModelOf[T, ID]) runSubscription(ctx context.Context, handler func(ctx context.Context, event *ChangeEvent[T]) error, opt SubscribeOptions) error {
+	tokens := coll.Native().Database().Collection(opt.TokenCollection)
+
+	// load the last saved token
+	var token bson.Raw
+	if opt.Name != "" {
+		var saved struct {
+			Token bson.Raw `bson:"token"`
+		}
+		err := tokens.FindOne(ctx, bson.M{"_id": opt.Name}).Decode(&saved)
+		if IsFindOneError(err) {
+			return err
+		}
+		token = saved.Token
+	}
+
+	delay := opt.RetryDelay
+	for {
+		err := coll.watchOnce(ctx, handler, opt, tokens, &token, &delay)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if isFatalWatchError(err) {
+			return err
+		}
+		if err != nil && opt.OnError != nil {
+			opt.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, opt.MaxRetryDelay)
+	}
+}
+
+// watchOnce - Run a single change stream until it fails, updating token as events are handled
+func (coll *ModelOf[T, ID]) watchOnce(ctx context.Context, handler func(ctx context.Context, event *ChangeEvent[T]) error, opt SubscribeOptions, tokens *mongo.Collection, token *bson.Raw, delay *time.Duration) error {
+	streamOpts := options.ChangeStream().SetFullDocument(opt.FullDocument)
+	if len(*token) > 0 {
+		streamOpts.SetResumeAfter(*token)
+	}
+
+	stream, err := coll.Watch(ctx, opt.Pipeline, streamOpts)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.WithoutCancel(ctx))
+
+	// start from the opening position, so events are not lost if the first one fails
+	if len(*token) == 0 {
+		*token = stream.ResumeToken()
+	}
+
+	for stream.Next(ctx) {
+		event, err := stream.Event()
+		if err != nil {
+			// restarting would decode the same event again, so move past it
+			if opt.OnError != nil {
+				opt.OnError(&EventDecodeError{ResumeToken: stream.ResumeToken(), Err: err})
+			}
+			if err = saveResumeToken(ctx, tokens, opt, token, stream.ResumeToken()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err = handler(ctx, event); err != nil {
+			return err
+		}
+
+		*delay = opt.RetryDelay
+		if err = saveResumeToken(ctx, tokens, opt, token, event.ResumeToken); err != nil {
+			return err
+		}
+	}
+
+	return stream.Err()
+}
+
+// saveResumeToken - Set token to next, and save it if the subscription has a name
+func saveResumeToken(ctx context.Context, tokens *mongo.Collection, opt SubscribeOptions, token *bson.Raw, next bson.Raw) error {
+	*token = next
+	if opt.Name == "" {
+		return nil
+	}
+
+	_, err := tokens.UpdateOne(ctx,
+		bson.M{"_id": opt.Name},
+		bson.M{"$set": bson.M{"token": next, "updatedAt": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
@@ -0,0 +1,219 @@
+package gmongo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func Test_isFatalWatchError(t *testing.T) {
+	assert.True(t, isFatalWatchError(mongo.CommandError{Code: 286}))
+	assert.True(t, isFatalWatchError(mongo.CommandError{Code: 280}))
+	assert.False(t, isFatalWatchError(mongo.CommandError{Code: 112}))
+	assert.False(t, isFatalWatchError(errors.New("network")))
+	assert.False(t, isFatalWatchError(nil))
+}
+
+func TestWatch(t *testing.T) {
+	client := testConnectToDb()
+	requireReplicaSet(t, client)
+
+	UserModel := MakeModel[*User](client.Database, "watch_users")
+	tokens := client.Database.Collection("test_resume_tokens")
+
+	reset := func() {
+		_, _ = UserModel.DeleteMany(bson.M{})
+		_, _ = tokens.DeleteMany(context.TODO(), bson.M{})
+	}
+
+	t.Run("Watch", func(t *testing.T) {
+		reset()
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+		defer cancel()
+
+		stream, err := UserModel.Watch(ctx, nil, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+		assert.Nil(t, err)
+		defer stream.Close(context.TODO())
+
+		user := &User{ID: primitive.NewObjectID(), Name: "John", Age: 20}
+		_, err = UserModel.InsertOne(user)
+		assert.Nil(t, err)
+		_, err = UserModel.UpdateOne(bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"age": 21}})
+		assert.Nil(t, err)
+
+		assert.True(t, stream.Next(ctx))
+		event, err := stream.Event()
+		assert.Nil(t, err)
+		assert.Equal(t, "insert", event.OperationType)
+		assert.Equal(t, "John", event.FullDocument.Name)
+		assert.NotEmpty(t, event.ResumeToken)
+
+		assert.True(t, stream.Next(ctx))
+		event, err = stream.Event()
+		assert.Nil(t, err)
+		assert.Equal(t, "update", event.OperationType)
+		assert.Equal(t, 21, event.FullDocument.Age)
+		assert.EqualValues(t, 21, event.UpdateDescription.UpdatedFields["age"])
+	})
+
+	t.Run("Events of encrypted models are decrypted", func(t *testing.T) {
+		CustomerModel := MakeModel[*Customer](client.Database, "watch_customers")
+		CustomerModel.EnableEncryption(testKeyProvider(t, "k1", "k1"))
+		_, _ = CustomerModel.DeleteMany(bson.M{})
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+		defer cancel()
+
+		stream, err := CustomerModel.Watch(ctx, nil, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+		assert.Nil(t, err)
+		defer stream.Close(context.TODO())
+
+		john := &Customer{ID: primitive.NewObjectID(), Name: "John", Email: "john@example.com", Age: 30, Contact: Contact{Phone: "123"}}
+		_, err = CustomerModel.InsertOne(john)
+		assert.Nil(t, err)
+		_, err = CustomerModel.UpdateOne(bson.M{"_id": john.ID}, bson.M{"$set": bson.M{"phone": "456"}})
+		assert.Nil(t, err)
+
+		assert.True(t, stream.Next(ctx))
+		event, err := stream.Event()
+		assert.Nil(t, err)
+		assert.Equal(t, *john, *event.FullDocument)
+		assert.Equal(t, stream.ResumeToken(), event.ResumeToken)
+
+		assert.True(t, stream.Next(ctx))
+		event, err = stream.Event()
+		assert.Nil(t, err)
+		assert.Equal(t, "456", event.FullDocument.Phone)
+		assert.Equal(t, "456", event.UpdateDescription.UpdatedFields["phone"])
+	})
+
+	t.Run("Subscribe resumes from saved token", func(t *testing.T) {
+		reset()
+
+		var mu sync.Mutex
+		var names []string
+		received := make(chan struct{}, 10)
+		handler := func(ctx context.Context, event *ChangeEvent[*User]) error {
+			mu.Lock()
+			names = append(names, event.FullDocument.Name)
+			mu.Unlock()
+			received <- struct{}{}
+			return nil
+		}
+		opt := SubscribeOptions{Name: "test", TokenCollection: tokens.Name()}
+
+		wait := func() {
+			select {
+			case <-received:
+			case <-time.After(10 * time.Second):
+				t.Fatal("timed out waiting for event")
+			}
+		}
+
+		sub := UserModel.Subscribe(context.TODO(), handler, opt)
+		time.Sleep(500 * time.Millisecond)
+		_, _ = UserModel.InsertOne(&User{ID: primitive.NewObjectID(), Name: "John"})
+		wait()
+		sub.Stop()
+
+		// inserted while no subscription is running
+		_, _ = UserModel.InsertOne(&User{ID: primitive.NewObjectID(), Name: "Doe"})
+
+		sub = UserModel.Subscribe(context.TODO(), handler, opt)
+		wait()
+		sub.Stop()
+
+		assert.Equal(t, []string{"John", "Doe"}, names)
+		assert.ErrorIs(t, sub.Wait(), context.Canceled)
+	})
+
+	t.Run("Subscribe redelivers after handler error", func(t *testing.T) {
+		reset()
+
+		var mu sync.Mutex
+		calls := 0
+		var errs []error
+		done := make(chan struct{})
+		sub := UserModel.Subscribe(context.TODO(), func(ctx context.Context, event *ChangeEvent[*User]) error {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			if calls == 1 {
+				return errors.New("boom")
+			}
+			close(done)
+			return nil
+		}, SubscribeOptions{
+			TokenCollection: tokens.Name(),
+			RetryDelay:      10 * time.Millisecond,
+			OnError: func(err error) {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			},
+		})
+		defer sub.Stop()
+
+		time.Sleep(500 * time.Millisecond)
+		_, _ = UserModel.InsertOne(&User{ID: primitive.NewObjectID(), Name: "John"})
+
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for redelivery")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, 2, calls)
+		assert.Len(t, errs, 1)
+	})
+	t.Run("Subscribe skips events that cannot be decoded", func(t *testing.T) {
+		reset()
+
+		var mu sync.Mutex
+		var errs []error
+		received := make(chan string, 10)
+		sub := UserModel.Subscribe(context.TODO(), func(ctx context.Context, event *ChangeEvent[*User]) error {
+			received <- event.FullDocument.Name
+			return nil
+		}, SubscribeOptions{
+			Name:            "test-decode",
+			TokenCollection: tokens.Name(),
+			RetryDelay:      10 * time.Millisecond,
+			OnError: func(err error) {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			},
+		})
+		defer sub.Stop()
+
+		time.Sleep(500 * time.Millisecond)
+		_, _ = UserModel.Native().InsertOne(context.TODO(), bson.M{"_id": primitive.NewObjectID(), "name": "Bad", "age": "twenty"})
+		_, _ = UserModel.InsertOne(&User{ID: primitive.NewObjectID(), Name: "John"})
+
+		select {
+		case name := <-received:
+			assert.Equal(t, "John", name)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Len(t, errs, 1)
+		var decodeErr *EventDecodeError
+		assert.ErrorAs(t, errs[0], &decodeErr)
+		assert.NotEmpty(t, decodeErr.ResumeToken)
+	})
+}